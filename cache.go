@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheEntry is data fetched from the Elastic Cloud API together with the time
+// it has been fetched, stored on disk to avoid fetching the same data for
+// every invocation.
+type cacheEntry[T any] struct {
+	Fetched time.Time `json:"fetched"`
+	Data    T         `json:"data"`
+}
+
+// expired returns true, if the entry is older than ttl.
+func (e cacheEntry[T]) expired(now time.Time, ttl time.Duration) bool {
+	return now.Sub(e.Fetched) > ttl
+}
+
+// cacheFilename returns the path of the cache file with the given name in the
+// user specific cache directory, e.g. ~/.cache/ec_check/<name> on Linux.
+func cacheFilename(name string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "ec_check", name), nil
+}
+
+// readCache reads the cache file with the given name. If the file does not
+// exist, the returned error wraps os.ErrNotExist.
+func readCache[T any](name string) (cacheEntry[T], error) {
+	var entry cacheEntry[T]
+
+	filename, err := cacheFilename(name)
+	if err != nil {
+		return entry, err
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return entry, err
+	}
+
+	err = json.Unmarshal(data, &entry)
+	if err != nil {
+		return entry, fmt.Errorf("failed to parse cache file %q: %w", filename, err)
+	}
+
+	return entry, nil
+}
+
+// writeCache writes data fetched at the given time to the cache file with the
+// given name. The data is written to a temporary file in the cache directory
+// first and then renamed, so concurrent invocations never read a partially
+// written cache file.
+func writeCache[T any](name string, data T, fetched time.Time) error {
+	filename, err := cacheFilename(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(filename), 0o755)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(cacheEntry[T]{Fetched: fetched, Data: data})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = tmp.Write(content)
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	err = tmp.Chmod(0o644)
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_writeCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	fetched := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, writeCache("test.json", []string{"a"}, fetched))
+	require.NoError(t, writeCache("test.json", []string{"b", "c"}, fetched.Add(time.Hour)))
+
+	entry, err := readCache[[]string]("test.json")
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "c"}, entry.Data)
+	require.True(t, fetched.Add(time.Hour).Equal(entry.Fetched))
+
+	// No temporary files are left behind.
+	filename, err := cacheFilename("test.json")
+	require.NoError(t, err)
+	files, err := os.ReadDir(filepath.Dir(filename))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+}
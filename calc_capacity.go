@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+)
+
+// tierDisk contains the disk size and the disk usage of a data tier summed up
+// over all nodes of the tier.
+type tierDisk struct {
+	nodes int
+	total float64
+	used  float64
+}
+
+// diskWatermark contains a disk watermark of Elasticsearch, which is either
+// given as percentage of the disk used or as absolute amount of free disk
+// space in bytes.
+type diskWatermark struct {
+	pct       float64
+	freeBytes float64
+}
+
+func (w diskWatermark) String() string {
+	if w.freeBytes > 0 {
+		return units.BytesSize(w.freeBytes) + " free"
+	}
+
+	return fmt.Sprintf("%.1f%%", w.pct)
+}
+
+// CapacityCheck contains the result of the check, if the data to be moved to a
+// tier fits into the tier.
+type CapacityCheck struct {
+	tier       Tier
+	disk       tierDisk
+	required   float64
+	maxFillPct float64
+	watermark  diskWatermark
+	allowed    float64
+}
+
+// Fits returns true, if the disk usage of the tier after moving the required
+// data stays within the allowed disk usage.
+func (c CapacityCheck) Fits() bool {
+	return c.disk.used+c.required <= c.allowed
+}
+
+func (c CapacityCheck) String() string {
+	usedAfter := c.disk.used + c.required
+
+	var usedPct, usedAfterPct, allowedPct float64
+	if c.disk.total > 0 {
+		usedPct = 100.0 / c.disk.total * c.disk.used
+		usedAfterPct = 100.0 / c.disk.total * usedAfter
+		allowedPct = 100.0 / c.disk.total * c.allowed
+	}
+
+	str := &strings.Builder{}
+	fmt.Fprintf(str, "Tier: %s (%d nodes)\n", c.tier, c.disk.nodes)
+	fmt.Fprintf(str, "Current Consumption: %s of %s (%.1f%%)\n", units.BytesSize(c.disk.used), units.BytesSize(c.disk.total), usedPct)
+	fmt.Fprintf(str, "Data to move: %s\n", units.BytesSize(c.required))
+	fmt.Fprintf(str, "Consumption after move: %s (%.1f%%)\n", units.BytesSize(usedAfter), usedAfterPct)
+	fmt.Fprintf(str, "Allowed consumption: %s (%.1f%%, max fill level: %.1f%%, low disk watermark: %s)\n", units.BytesSize(c.allowed), allowedPct, c.maxFillPct, c.watermark)
+	fmt.Fprintf(str, "Data fits into tier: %t", c.Fits())
+
+	return str.String()
+}
+
+// tierDiskUsage sums up the disk size and disk usage per tier from the
+// allocation information. Nodes without data tier role are ignored.
+func tierDiskUsage(allocations []Allocation) (map[Tier]tierDisk, error) {
+	tiers := make(map[Tier]tierDisk, 4)
+	for _, alloc := range allocations {
+		if !strings.ContainsAny(alloc.NodeRole, "hwcf") {
+			continue
+		}
+
+		tier := tierFromNodeRole(alloc.NodeRole)
+
+		diskTotal, err := strconv.ParseFloat(alloc.DiskTotal, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid disk.total %q of %s node: %w", alloc.DiskTotal, tier, err)
+		}
+
+		diskUsed, err := strconv.ParseFloat(alloc.DiskUsed, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid disk.used %q of %s node: %w", alloc.DiskUsed, tier, err)
+		}
+
+		disk := tiers[tier]
+		disk.nodes++
+		disk.total += diskTotal
+		disk.used += diskUsed
+		tiers[tier] = disk
+	}
+
+	return tiers, nil
+}
+
+// parseDiskWatermark parses a disk watermark setting of Elasticsearch, which
+// is either a percentage (e.g. 85%), a ratio (e.g. 0.85) or an absolute byte
+// value of free disk space (e.g. 500gb).
+func parseDiskWatermark(watermark string) (diskWatermark, error) {
+	watermark = strings.TrimSpace(watermark)
+
+	if pct, ok := strings.CutSuffix(watermark, "%"); ok {
+		value, err := strconv.ParseFloat(pct, 64)
+		if err != nil {
+			return diskWatermark{}, fmt.Errorf("invalid disk watermark %q: %w", watermark, err)
+		}
+
+		return diskWatermark{pct: value}, nil
+	}
+
+	if ratio, err := strconv.ParseFloat(watermark, 64); err == nil {
+		return diskWatermark{pct: ratio * 100}, nil
+	}
+
+	freeBytes, err := units.RAMInBytes(watermark)
+	if err != nil {
+		return diskWatermark{}, fmt.Errorf("invalid disk watermark %q: %w", watermark, err)
+	}
+
+	return diskWatermark{freeBytes: float64(freeBytes)}, nil
+}
+
+// calcCapacityCheck calculates, if the required disk space fits into the tier.
+// The allowed disk usage is limited by the max fill level as well as by the
+// low disk watermark, above which Elasticsearch does not allocate shards to a
+// node anymore.
+func calcCapacityCheck(tier Tier, disk tierDisk, required float64, maxFillPct float64, watermark diskWatermark) CapacityCheck {
+	allowed := disk.total / 100.0 * maxFillPct
+
+	watermarkAllowed := disk.total / 100.0 * watermark.pct
+	if watermark.freeBytes > 0 {
+		watermarkAllowed = disk.total - float64(disk.nodes)*watermark.freeBytes
+	}
+
+	if watermarkAllowed < allowed {
+		allowed = watermarkAllowed
+	}
+
+	return CapacityCheck{
+		tier:       tier,
+		disk:       disk,
+		required:   required,
+		maxFillPct: maxFillPct,
+		watermark:  watermark,
+		allowed:    allowed,
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseDiskWatermark(t *testing.T) {
+	tests := []struct {
+		watermark string
+
+		wantWatermark diskWatermark
+		wantErr       bool
+	}{
+		{
+			watermark: "85%",
+
+			wantWatermark: diskWatermark{pct: 85},
+		},
+		{
+			watermark: "0.9",
+
+			wantWatermark: diskWatermark{pct: 90},
+		},
+		{
+			watermark: "500gb",
+
+			wantWatermark: diskWatermark{freeBytes: 500 * 1024 * 1024 * 1024},
+		},
+		{
+			watermark: "invalid",
+
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.watermark, func(t *testing.T) {
+			watermark, err := parseDiskWatermark(tc.watermark)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.InDelta(t, tc.wantWatermark.pct, watermark.pct, 0.0001)
+			require.Equal(t, tc.wantWatermark.freeBytes, watermark.freeBytes)
+		})
+	}
+}
+
+func Test_calcCapacityCheck(t *testing.T) {
+	allocations := []Allocation{
+		{NodeRole: ""}, // unassigned shards
+		{NodeRole: "mv"},
+		{NodeRole: "himrst", DiskUsed: "500", DiskTotal: "1000"},
+		{NodeRole: "c", DiskUsed: "200", DiskTotal: "1000"},
+		{NodeRole: "c", DiskUsed: "300", DiskTotal: "1000"},
+	}
+
+	disk, err := tierDiskUsage(allocations)
+	require.NoError(t, err)
+	require.Equal(t, tierDisk{nodes: 2, total: 2000, used: 500}, disk[tierCold])
+	require.Equal(t, tierDisk{nodes: 1, total: 1000, used: 500}, disk[tierHot])
+
+	_, err = tierDiskUsage([]Allocation{{NodeRole: "w", DiskUsed: "500", DiskTotal: "1000b"}})
+	require.Error(t, err)
+
+	tests := []struct {
+		name       string
+		required   float64
+		maxFillPct float64
+		watermark  diskWatermark
+
+		wantAllowed float64
+		wantFits    bool
+	}{
+		{
+			name:       "fits",
+			required:   1000,
+			maxFillPct: 85,
+			watermark:  diskWatermark{pct: 90},
+
+			wantAllowed: 1700,
+			wantFits:    true,
+		},
+		{
+			name:       "exceeds max fill level",
+			required:   1300,
+			maxFillPct: 85,
+			watermark:  diskWatermark{pct: 90},
+
+			wantAllowed: 1700,
+			wantFits:    false,
+		},
+		{
+			name:       "exceeds watermark percentage",
+			required:   1000,
+			maxFillPct: 90,
+			watermark:  diskWatermark{pct: 70},
+
+			wantAllowed: 1400,
+			wantFits:    false,
+		},
+		{
+			name:       "exceeds absolute watermark",
+			required:   1000,
+			maxFillPct: 90,
+			watermark:  diskWatermark{freeBytes: 300},
+
+			wantAllowed: 1400,
+			wantFits:    false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			check := calcCapacityCheck(tierCold, disk[tierCold], tc.required, tc.maxFillPct, tc.watermark)
+
+			require.InDelta(t, tc.wantAllowed, check.allowed, 0.0001)
+			require.Equal(t, tc.wantFits, check.Fits())
+		})
+	}
+}
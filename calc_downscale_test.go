@@ -426,6 +426,58 @@ func Test_calcDownscaleRecommendation(t *testing.T) {
 	}
 }
 
+func Test_tierSizesFromTemplate(t *testing.T) {
+	template := DeploymentTemplate{
+		InstanceConfigurations: []InstanceConfiguration{
+			{
+				ID:                "azure.es.datahot.edsv4",
+				StorageMultiplier: 35,
+				DiscreteSizes:     DiscreteSizes{Sizes: []int{1024, 2048, 4096}, Resource: "memory"},
+			},
+			{
+				ID:            "azure.kibana.fsv2",
+				DiscreteSizes: DiscreteSizes{Sizes: []int{1024}, Resource: "memory"},
+			},
+		},
+	}
+
+	got := tierSizesFromTemplate(template)
+
+	require.Len(t, got, 1)
+	require.Len(t, got[tierHot], 3+maxNodesPerTier-1)
+	require.Equal(t, Size{Memory: 4096 * mibMultiplier, Disk: 4096 * 35 * mibMultiplier}, got[tierHot][2])
+	// Full nodes multiply memory and disk of the largest node size.
+	require.Equal(t, Size{Memory: 2 * 4096 * mibMultiplier, Disk: 2 * 4096 * 35 * mibMultiplier}, got[tierHot][3])
+	require.Equal(t, Size{Memory: maxNodesPerTier * 4096 * mibMultiplier, Disk: maxNodesPerTier * 4096 * 35 * mibMultiplier}, got[tierHot][len(got[tierHot])-1])
+}
+
+func Test_calcDownscaleRecommendationFullNodes(t *testing.T) {
+	template := DeploymentTemplate{
+		InstanceConfigurations: []InstanceConfiguration{
+			{
+				ID:                "azure.es.datahot.edsv4",
+				StorageMultiplier: 35,
+				DiscreteSizes:     DiscreteSizes{Sizes: []int{1024, 2048, 4096}, Resource: "memory"},
+			},
+		},
+	}
+
+	fullNodeDisk := 4096 * 35.0 * mibMultiplier
+	allocations := []Allocation{
+		{
+			NodeRole:  "h",
+			DiskUsed:  fmt.Sprintf("%.0f", fullNodeDisk),
+			DiskTotal: fmt.Sprintf("%.0f", 3*fullNodeDisk),
+		},
+	}
+
+	recommendations := calcDownscaleRecommendation(allocations, tierSizesFromTemplate(template), 25.0, false)
+
+	require.True(t, recommendations[tierHot].isDownscalingRecommended)
+	require.Equal(t, 3*4096.0*mibMultiplier, recommendations[tierHot].currentMemoryPerNode)
+	require.Equal(t, 2*4096.0*mibMultiplier, recommendations[tierHot].smallerMemoryPerNode)
+}
+
 func Test_tierFromNodeRole(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// phaseTransition is an upcoming transition of an index from one ILM phase to
+// the next one. leavingSize is the size of the index, which leaves the data
+// tier of the from phase, enteringSize the size, which enters the data tier of
+// the to phase.
+type phaseTransition struct {
+	index        string
+	policy       string
+	from         string
+	to           string
+	at           time.Time
+	leavingSize  int64
+	enteringSize int64
+}
+
+// tierFlow contains the number of indices and the data volume entering and
+// leaving a data tier.
+type tierFlow struct {
+	indicesIn  int
+	indicesOut int
+	sizeIn     int64
+	sizeOut    int64
+}
+
+// forecastPeriod contains the tierFlow per tier for the transitions within a
+// period (day or week) starting at start.
+type forecastPeriod struct {
+	start time.Time
+	tiers map[string]tierFlow
+}
+
+// phaseSize returns the size an index occupies in the data tier of the given
+// phase of the policy. Indices in a phase with searchable snapshot action are
+// mounted from a snapshot and occupy the size of the primary shards only.
+// Indices in the delete phase do not occupy any space.
+func phaseSize(policy types.IlmPolicy, phase string, index indexDetails) int64 {
+	if phase == "delete" {
+		return 0
+	}
+
+	definition := policyPhase(policy, phase)
+	if definition != nil && definition.Actions != nil && definition.Actions.SearchableSnapshot != nil {
+		return index.priSize
+	}
+
+	return index.totalSize
+}
+
+// calcPhaseTransitions calculates the phase transitions of the indices, which
+// are expected within horizon from now based on the min_age of the phases of
+// the policies and the age of the indices. An index can pass multiple phases
+// within the horizon. Indices, which already exceed the min_age of the next
+// phase, are expected to transition now. Indices with unknown policies are
+// ignored as well as indices waiting for rollover, since their age is reset
+// with the rollover.
+func calcPhaseTransitions(indices []indexDetails, policies map[string]types.IlmPolicy, now time.Time, horizon time.Duration) ([]phaseTransition, error) {
+	end := now.Add(horizon)
+
+	var transitions []phaseTransition
+	for _, index := range indices {
+		policy, ok := policies[index.policy]
+		if !ok || index.writeIndex || index.step == "check-rollover-ready" {
+			continue
+		}
+
+		phase := index.phase
+		size := phaseSize(policy, phase, index)
+		for {
+			next, minAge, err := nextPhase(policy, phase)
+			if err != nil {
+				return nil, err
+			}
+
+			if next == "" {
+				break
+			}
+
+			at := now.Add(minAge - index.age)
+			if at.Before(now) {
+				at = now
+			}
+
+			if at.After(end) {
+				break
+			}
+
+			nextSize := phaseSize(policy, next, index)
+			transitions = append(transitions, phaseTransition{
+				index:        index.name,
+				policy:       index.policy,
+				from:         phase,
+				to:           next,
+				at:           at,
+				leavingSize:  size,
+				enteringSize: nextSize,
+			})
+
+			phase, size = next, nextSize
+		}
+	}
+
+	sort.Slice(transitions, func(i, j int) bool {
+		if !transitions[i].at.Equal(transitions[j].at) {
+			return transitions[i].at.Before(transitions[j].at)
+		}
+
+		return transitions[i].index < transitions[j].index
+	})
+
+	return transitions, nil
+}
+
+// periodStart returns the start of the period (day or week) t belongs to in
+// UTC. Weeks start on Monday.
+func periodStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if interval == "week" {
+		daysSinceMonday := (int(start.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -daysSinceMonday)
+	}
+
+	return start
+}
+
+// calcForecast sums up the data volume entering and leaving each data tier
+// per period (day or week) from the phase transitions. The delete phase is
+// not a data tier, therefore indices entering the delete phase only count as
+// leaving their current tier. New indices are already allocated to the hot
+// tier, therefore their transition to the first phase does not move any data.
+// The periods are sorted by start.
+func calcForecast(transitions []phaseTransition, interval string) []forecastPeriod {
+	periods := make(map[time.Time]map[string]tierFlow)
+	for _, transition := range transitions {
+		if transition.from == "new" {
+			continue
+		}
+
+		start := periodStart(transition.at, interval)
+
+		tiers, ok := periods[start]
+		if !ok {
+			tiers = make(map[string]tierFlow, 4)
+			periods[start] = tiers
+		}
+
+		out := tiers[transition.from]
+		out.indicesOut++
+		out.sizeOut += transition.leavingSize
+		tiers[transition.from] = out
+
+		if transition.to != "delete" {
+			in := tiers[transition.to]
+			in.indicesIn++
+			in.sizeIn += transition.enteringSize
+			tiers[transition.to] = in
+		}
+	}
+
+	forecast := make([]forecastPeriod, 0, len(periods))
+	for start, tiers := range periods {
+		forecast = append(forecast, forecastPeriod{start: start, tiers: tiers})
+	}
+
+	sort.Slice(forecast, func(i, j int) bool {
+		return forecast[i].start.Before(forecast[j].start)
+	})
+
+	return forecast
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_calcPhaseTransitions(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	policies := map[string]types.IlmPolicy{
+		"logs": {
+			Phases: types.Phases{
+				Hot:    &types.Phase{},
+				Warm:   &types.Phase{MinAge: "7d"},
+				Cold:   &types.Phase{MinAge: "10d", Actions: &types.IlmActions{SearchableSnapshot: &types.SearchableSnapshotAction{SnapshotRepository: "found-snapshots"}}},
+				Delete: &types.Phase{MinAge: "90d"},
+			},
+		},
+	}
+
+	indices := []indexDetails{
+		{name: "logs-1", policy: "logs", phase: "hot", step: "complete", age: 6 * day, priSize: 10, totalSize: 20},
+		{name: "logs-2", policy: "logs", phase: "warm", step: "complete", age: 9 * day, priSize: 5, totalSize: 10},
+		{name: "logs-3", policy: "logs", phase: "cold", step: "complete", age: 95 * day, priSize: 1, totalSize: 2},
+		{name: "logs-4", policy: "logs", phase: "hot", step: "check-rollover-ready", age: 30 * day},
+		{name: "logs-5", policy: "logs", phase: "hot", step: "complete", writeIndex: true, age: 30 * day},
+		{name: "other-1", policy: "unknown", phase: "hot", age: 30 * day},
+	}
+
+	transitions, err := calcPhaseTransitions(indices, policies, now, 7*day)
+	require.NoError(t, err)
+
+	want := []phaseTransition{
+		{index: "logs-3", policy: "logs", from: "cold", to: "delete", at: now, leavingSize: 1, enteringSize: 0},
+		{index: "logs-1", policy: "logs", from: "hot", to: "warm", at: now.Add(day), leavingSize: 20, enteringSize: 20},
+		{index: "logs-2", policy: "logs", from: "warm", to: "cold", at: now.Add(day), leavingSize: 10, enteringSize: 5},
+		{index: "logs-1", policy: "logs", from: "warm", to: "cold", at: now.Add(4 * day), leavingSize: 20, enteringSize: 10},
+	}
+	require.Equal(t, want, transitions)
+
+	forecast := calcForecast(transitions, "day")
+	require.Equal(t, []forecastPeriod{
+		{
+			start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			tiers: map[string]tierFlow{
+				"cold": {indicesOut: 1, sizeOut: 1},
+			},
+		},
+		{
+			start: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+			tiers: map[string]tierFlow{
+				"hot":  {indicesOut: 1, sizeOut: 20},
+				"warm": {indicesIn: 1, sizeIn: 20, indicesOut: 1, sizeOut: 10},
+				"cold": {indicesIn: 1, sizeIn: 5},
+			},
+		},
+		{
+			start: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+			tiers: map[string]tierFlow{
+				"warm": {indicesOut: 1, sizeOut: 20},
+				"cold": {indicesIn: 1, sizeIn: 10},
+			},
+		},
+	}, forecast)
+
+	forecast = calcForecast(transitions, "week")
+	require.Len(t, forecast, 1)
+	require.Equal(t, time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), forecast[0].start)
+	require.Equal(t, tierFlow{indicesIn: 2, sizeIn: 15, indicesOut: 1, sizeOut: 1}, forecast[0].tiers["cold"])
+	require.Equal(t, tierFlow{indicesIn: 1, sizeIn: 20, indicesOut: 2, sizeOut: 30}, forecast[0].tiers["warm"])
+}
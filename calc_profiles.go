@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// profileTierTotal is the tier of the profileTierOption containing the sums
+// of all tiers of a profile.
+const profileTierTotal Tier = "total"
+
+// profileTierOption is the smallest configuration of a tier in a profile,
+// which keeps the required headroom for the current disk usage of the tier.
+// The number of nodes of the tier is kept. fits is false, if the tier is not
+// available in the profile or the disk usage does not fit into the largest
+// configuration of the tier with the required headroom.
+type profileTierOption struct {
+	profile    string
+	tier       Tier
+	nodes      int
+	nodeMemory float64
+	nodeDisk   float64
+	memory     float64
+	disk       float64
+	used       float64
+	fits       bool
+}
+
+// freePct returns the free disk space in percent after the change to the
+// configuration.
+func (o profileTierOption) freePct() float64 {
+	if o.disk == 0 {
+		return 0
+	}
+
+	return 100.0 / o.disk * (o.disk - o.used)
+}
+
+// calcProfileOptions calculates for every deployment template (profile) the
+// smallest configuration of each tier, which keeps the required headroom for
+// the current disk usage of the tier. For each profile, the options of the
+// tiers are followed by the total over all tiers. The profiles are sorted by
+// total memory with profiles, which do not fit the current disk usage, last.
+func calcProfileOptions(usage map[Tier]tierDisk, deploymentTemplates DeploymentTemplates, headroomPercent float64) []profileTierOption {
+	tiers := make([]Tier, 0, len(usage))
+	for tier := range usage {
+		tiers = append(tiers, tier)
+	}
+
+	sort.Slice(tiers, func(i, j int) bool {
+		return phaseLess(string(tiers[i]), string(tiers[j]))
+	})
+
+	profiles := make([][]profileTierOption, 0, len(deploymentTemplates))
+	for _, deploymentTemplate := range deploymentTemplates {
+		tierSizes := tierSizesFromTemplate(deploymentTemplate)
+		if len(tierSizes) == 0 {
+			continue
+		}
+
+		options := make([]profileTierOption, 0, len(tiers)+1)
+		total := profileTierOption{profile: deploymentTemplate.ID, tier: profileTierTotal, fits: true}
+		for _, tier := range tiers {
+			option := smallestTierOption(deploymentTemplate.ID, tier, usage[tier], tierSizes[tier], headroomPercent)
+			options = append(options, option)
+
+			total.nodes += option.nodes
+			total.memory += option.memory
+			total.disk += option.disk
+			total.used += option.used
+			total.fits = total.fits && option.fits
+		}
+
+		profiles = append(profiles, append(options, total))
+	}
+
+	sort.SliceStable(profiles, func(i, j int) bool {
+		a, b := profiles[i][len(profiles[i])-1], profiles[j][len(profiles[j])-1]
+		if a.fits != b.fits {
+			return a.fits
+		}
+
+		return a.memory < b.memory
+	})
+
+	var options []profileTierOption
+	for _, profile := range profiles {
+		options = append(options, profile...)
+	}
+
+	return options
+}
+
+// smallestTierOption returns the smallest of the sizes, which keeps the
+// required headroom for the disk usage with the current number of nodes of
+// the tier. The sizes are expected to be sorted ascending.
+func smallestTierOption(profile string, tier Tier, usage tierDisk, sizes []Size, headroomPercent float64) profileTierOption {
+	option := profileTierOption{
+		profile: profile,
+		tier:    tier,
+		nodes:   usage.nodes,
+		used:    usage.used,
+	}
+
+	for _, size := range sizes {
+		disk := float64(usage.nodes) * size.Disk
+		if disk == 0 || 100.0/disk*(disk-usage.used) < headroomPercent {
+			continue
+		}
+
+		option.nodeMemory = size.Memory
+		option.nodeDisk = size.Disk
+		option.memory = float64(usage.nodes) * size.Memory
+		option.disk = disk
+		option.fits = true
+
+		break
+	}
+
+	return option
+}
+
+// profileDiskTolerance is the maximum relative deviation of the disk size of a
+// node from the nearest disk size of its tier in a profile for the node to
+// match the profile.
+const profileDiskTolerance = 0.1
+
+// profileMatch contains the maximum relative deviation of the disk sizes of
+// the data nodes of a deployment from the nearest disk sizes of their tiers
+// in a profile.
+type profileMatch struct {
+	profile   string
+	deviation float64
+}
+
+// matchProfiles matches the disk sizes of the data nodes against the disk
+// sizes of the tiers of every deployment template (profile), which depend on
+// the storage_multiplier of the instance configurations. The matches are
+// sorted by deviation, the best matching profile first. If the profile does
+// not provide a tier of a node, the deviation is infinite.
+func matchProfiles(allocations []Allocation, deploymentTemplates DeploymentTemplates) []profileMatch {
+	type node struct {
+		tier Tier
+		disk float64
+	}
+
+	var nodes []node
+	for _, alloc := range allocations {
+		if !strings.ContainsAny(alloc.NodeRole, "hwcf") {
+			continue
+		}
+
+		disk, err := strconv.ParseFloat(alloc.DiskTotal, 64)
+		if err != nil || disk == 0 {
+			continue
+		}
+
+		nodes = append(nodes, node{tier: tierFromNodeRole(alloc.NodeRole), disk: disk})
+	}
+
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	matches := make([]profileMatch, 0, len(deploymentTemplates))
+	for _, deploymentTemplate := range deploymentTemplates {
+		tierSizes := tierSizesFromTemplate(deploymentTemplate)
+
+		match := profileMatch{profile: deploymentTemplate.ID}
+		for _, n := range nodes {
+			nodeDeviation := math.Inf(1)
+			for _, size := range tierSizes[n.tier] {
+				nodeDeviation = min(nodeDeviation, delta(size.Disk, n.disk)/n.disk)
+			}
+
+			match.deviation = max(match.deviation, nodeDeviation)
+		}
+
+		matches = append(matches, match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].deviation < matches[j].deviation
+	})
+
+	return matches
+}
+
+// selectProfile returns the given profile or, if no profile is given, the
+// best matching profile of the matches. If the given profile does not match
+// the disk sizes of the nodes, a warning with the best matching profile is
+// written to w, since the sizes of the profile would lead to wrong results.
+func selectProfile(w io.Writer, profile string, matches []profileMatch) (string, error) {
+	if len(matches) == 0 {
+		if profile == "" {
+			return "", fmt.Errorf("failed to detect the profile, no data nodes found, use --profile")
+		}
+
+		return profile, nil
+	}
+
+	best := matches[0]
+
+	if profile == "" {
+		if best.deviation > profileDiskTolerance {
+			return "", fmt.Errorf("failed to detect the profile, the disk sizes of the nodes do not match any profile (best match %q deviates by %s), use --profile", best.profile, formatDeviation(best.deviation))
+		}
+
+		var ambiguous []string
+		for _, match := range matches[1:] {
+			if match.deviation == best.deviation {
+				ambiguous = append(ambiguous, match.profile)
+			}
+		}
+
+		if len(ambiguous) > 0 {
+			fmt.Fprintf(w, "WARNING: the disk sizes of the nodes match the profiles %s equally well, using %q, use --profile to select another one\n", strings.Join(append([]string{best.profile}, ambiguous...), ", "), best.profile)
+		}
+
+		fmt.Fprintf(w, "Detected profile: %s\n\n", best.profile)
+
+		return best.profile, nil
+	}
+
+	for _, match := range matches {
+		if match.profile != profile {
+			continue
+		}
+
+		if match.deviation > profileDiskTolerance {
+			fmt.Fprintf(w, "WARNING: the disk sizes of the nodes do not match profile %q (deviation %s), the results are likely wrong, the best matching profile is %q (deviation %s)\n\n", profile, formatDeviation(match.deviation), best.profile, formatDeviation(best.deviation))
+		}
+
+		return profile, nil
+	}
+
+	return "", fmt.Errorf("profile %q is not available in the region", profile)
+}
+
+// formatDeviation returns the relative deviation as percentage.
+func formatDeviation(deviation float64) string {
+	if math.IsInf(deviation, 1) {
+		return "∞ (tier missing)"
+	}
+
+	return fmt.Sprintf("%.1f%%", deviation*100)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testDeploymentTemplate(id string, multipliers map[string]float64) DeploymentTemplate {
+	template := DeploymentTemplate{ID: id}
+	for tier, multiplier := range multipliers {
+		template.InstanceConfigurations = append(template.InstanceConfigurations, InstanceConfiguration{
+			ID:                "azure.es.data" + tier + ".test",
+			StorageMultiplier: multiplier,
+			DiscreteSizes:     DiscreteSizes{Sizes: []int{1024, 2048, 4096}, Resource: "memory"},
+		})
+	}
+
+	return template
+}
+
+func Test_calcProfileOptions(t *testing.T) {
+	const gib = 1024 * mibMultiplier
+
+	usage := map[Tier]tierDisk{
+		tierWarm: {nodes: 1, used: 500 * gib},
+		tierHot:  {nodes: 2, used: 100 * gib},
+	}
+
+	templates := DeploymentTemplates{
+		testDeploymentTemplate("azure-general-purpose", map[string]float64{"hot": 35}),
+		testDeploymentTemplate("azure-cpu-optimized", map[string]float64{"hot": 10, "warm": 80}),
+		testDeploymentTemplate("azure-storage-optimized", map[string]float64{"hot": 35, "warm": 160}),
+		{ID: "azure-observability"},
+	}
+
+	options := calcProfileOptions(usage, templates, 25)
+
+	type summary struct {
+		profile    string
+		tier       Tier
+		nodes      int
+		nodeMemory float64
+		memory     float64
+		fits       bool
+	}
+
+	got := make([]summary, 0, len(options))
+	for _, o := range options {
+		got = append(got, summary{profile: o.profile, tier: o.tier, nodes: o.nodes, nodeMemory: o.nodeMemory, memory: o.memory, fits: o.fits})
+	}
+
+	require.Equal(t, []summary{
+		{profile: "azure-storage-optimized", tier: tierHot, nodes: 2, nodeMemory: 2 * gib, memory: 4 * gib, fits: true},
+		{profile: "azure-storage-optimized", tier: tierWarm, nodes: 1, nodeMemory: 8 * gib, memory: 8 * gib, fits: true},
+		{profile: "azure-storage-optimized", tier: profileTierTotal, nodes: 3, memory: 12 * gib, fits: true},
+		{profile: "azure-cpu-optimized", tier: tierHot, nodes: 2, nodeMemory: 8 * gib, memory: 16 * gib, fits: true},
+		{profile: "azure-cpu-optimized", tier: tierWarm, nodes: 1, nodeMemory: 12 * gib, memory: 12 * gib, fits: true},
+		{profile: "azure-cpu-optimized", tier: profileTierTotal, nodes: 3, memory: 28 * gib, fits: true},
+		{profile: "azure-general-purpose", tier: tierHot, nodes: 2, nodeMemory: 2 * gib, memory: 4 * gib, fits: true},
+		{profile: "azure-general-purpose", tier: tierWarm, nodes: 1, fits: false},
+		{profile: "azure-general-purpose", tier: profileTierTotal, nodes: 3, memory: 4 * gib, fits: false},
+	}, got)
+
+	require.InDelta(t, 100.0/140*40, options[0].freePct(), 0.001)
+}
+
+func Test_matchProfiles(t *testing.T) {
+	const gib = 1024 * mibMultiplier
+
+	templates := DeploymentTemplates{
+		testDeploymentTemplate("azure-general-purpose", map[string]float64{"hot": 35}),
+		testDeploymentTemplate("azure-storage-optimized", map[string]float64{"hot": 35, "warm": 160}),
+		testDeploymentTemplate("azure-cpu-optimized", map[string]float64{"hot": 10, "warm": 80}),
+	}
+
+	allocations := []Allocation{
+		{NodeRole: "himrst", DiskTotal: fmt.Sprint(int64(70 * gib))},
+		{NodeRole: "himrst", DiskTotal: fmt.Sprint(int64(72 * gib))},
+		{NodeRole: "w", DiskTotal: fmt.Sprint(int64(640 * gib))},
+		{NodeRole: "mr", DiskTotal: fmt.Sprint(int64(1 * gib))},
+	}
+
+	matches := matchProfiles(allocations, templates)
+	require.Len(t, matches, 3)
+	require.Equal(t, "azure-storage-optimized", matches[0].profile)
+	require.InDelta(t, 2.0/72, matches[0].deviation, 0.0001)
+	require.Equal(t, "azure-cpu-optimized", matches[1].profile)
+	require.InDelta(t, 10.0/70, matches[1].deviation, 0.0001)
+	require.Equal(t, "azure-general-purpose", matches[2].profile)
+	require.True(t, math.IsInf(matches[2].deviation, 1))
+
+	require.Empty(t, matchProfiles([]Allocation{{NodeRole: "mr", DiskTotal: "1024"}}, templates))
+}
+
+func Test_selectProfile(t *testing.T) {
+	matches := []profileMatch{
+		{profile: "azure-storage-optimized", deviation: 0.01},
+		{profile: "azure-storage-optimized-v2", deviation: 0.01},
+		{profile: "azure-cpu-optimized", deviation: 0.5},
+	}
+
+	tests := []struct {
+		name    string
+		profile string
+		matches []profileMatch
+
+		want        string
+		wantErr     bool
+		wantWarning string
+	}{
+		{
+			name:        "detected, ambiguous",
+			matches:     matches,
+			want:        "azure-storage-optimized",
+			wantWarning: "WARNING: the disk sizes of the nodes match the profiles azure-storage-optimized, azure-storage-optimized-v2 equally well",
+		},
+		{
+			name:    "detected",
+			matches: matches[1:],
+			want:    "azure-storage-optimized-v2",
+		},
+		{
+			name:    "no match",
+			matches: matches[2:],
+			wantErr: true,
+		},
+		{
+			name:    "no data nodes",
+			wantErr: true,
+		},
+		{
+			name:    "explicit",
+			profile: "azure-storage-optimized-v2",
+			matches: matches,
+			want:    "azure-storage-optimized-v2",
+		},
+		{
+			name:        "explicit, mismatch",
+			profile:     "azure-cpu-optimized",
+			matches:     matches,
+			want:        "azure-cpu-optimized",
+			wantWarning: `WARNING: the disk sizes of the nodes do not match profile "azure-cpu-optimized" (deviation 50.0%)`,
+		},
+		{
+			name:    "explicit, unknown",
+			profile: "azure-memory-optimized",
+			matches: matches,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+
+			profile, err := selectProfile(w, tc.profile, tc.matches)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, profile)
+
+			if tc.wantWarning != "" {
+				require.Contains(t, w.String(), tc.wantWarning)
+			} else {
+				require.NotContains(t, w.String(), "WARNING")
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"time"
+)
+
+// retentionIndex contains the details of an ILM managed index, which are
+// relevant to calculate the impact of a changed retention.
+type retentionIndex struct {
+	policy     string
+	phase      string
+	step       string
+	writeIndex bool
+	age        time.Duration
+	totalSize  int64
+}
+
+// phaseRetention contains the number and the total size of the indices in a
+// phase of a policy as well as the part of it, which would be deleted with the
+// changed retention.
+type phaseRetention struct {
+	indices        int
+	totalSize      int64
+	deletedIndices int
+	freedSize      int64
+}
+
+// policyRetention contains the current delete min_age of a policy and the
+// impact of the changed retention for each phase of the policy.
+type policyRetention struct {
+	deleteMinAge   time.Duration
+	hasDeletePhase bool
+	phases         map[string]phaseRetention
+}
+
+// RetentionImpact contains the policyRetention for each ILM policy.
+type RetentionImpact map[string]policyRetention
+
+// calcRetentionImpact calculates for each policy, which indices would be
+// deleted, if the min_age of the delete phase would be changed to
+// newDeleteMinAge. deleteMinAges contains the current min_age of the delete
+// phase for each policy, policies without delete phase are not contained.
+// Indices of policies without delete phase are only considered, if
+// addDeletePhase is true, i.e. a delete phase would be added to these
+// policies. Indices, which are already in the delete phase, are not
+// considered as freed, since they are deleted regardless of the change. Write
+// indices and indices waiting for rollover are not considered as freed
+// either, since their age is reset with the rollover.
+func calcRetentionImpact(deleteMinAges map[string]time.Duration, indices []retentionIndex, newDeleteMinAge time.Duration, addDeletePhase bool) RetentionImpact {
+	impact := make(RetentionImpact)
+	for _, index := range indices {
+		policy, ok := impact[index.policy]
+		if !ok {
+			policy.deleteMinAge, policy.hasDeletePhase = deleteMinAges[index.policy]
+			policy.phases = make(map[string]phaseRetention, len(ilmPhases))
+		}
+
+		phase := policy.phases[index.phase]
+		phase.indices++
+		phase.totalSize += index.totalSize
+
+		deletable := (policy.hasDeletePhase || addDeletePhase) &&
+			index.phase != "delete" &&
+			!index.writeIndex &&
+			index.step != "check-rollover-ready"
+
+		if deletable && index.age >= newDeleteMinAge {
+			phase.deletedIndices++
+			phase.freedSize += index.totalSize
+		}
+
+		policy.phases[index.phase] = phase
+		impact[index.policy] = policy
+	}
+
+	return impact
+}
+
+// PerTier returns the total size and the freed size summed up over all
+// policies for each phase, which corresponds to the data tier the indices are
+// stored on.
+func (r RetentionImpact) PerTier() map[string]phaseRetention {
+	tiers := make(map[string]phaseRetention, len(ilmPhases))
+	for _, policy := range r {
+		for phaseName, phase := range policy.phases {
+			tier := tiers[phaseName]
+			tier.indices += phase.indices
+			tier.totalSize += phase.totalSize
+			tier.deletedIndices += phase.deletedIndices
+			tier.freedSize += phase.freedSize
+			tiers[phaseName] = tier
+		}
+	}
+
+	return tiers
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_calcRetentionImpact(t *testing.T) {
+	deleteMinAges := map[string]time.Duration{
+		"logs": 90 * day,
+	}
+
+	indices := []retentionIndex{
+		{policy: "logs", phase: "hot", age: 1 * day, totalSize: 100},
+		{policy: "logs", phase: "warm", age: 20 * day, totalSize: 200},
+		{policy: "logs", phase: "warm", age: 40 * day, totalSize: 300},
+		{policy: "logs", phase: "cold", age: 80 * day, totalSize: 400},
+		{policy: "logs", phase: "delete", age: 91 * day, totalSize: 500},
+		{policy: "logs", phase: "hot", writeIndex: true, age: 35 * day, totalSize: 2000},
+		{policy: "logs", phase: "hot", step: "check-rollover-ready", age: 35 * day, totalSize: 4000},
+		{policy: "metrics", phase: "hot", age: 60 * day, totalSize: 1000},
+	}
+
+	tests := []struct {
+		name            string
+		newDeleteMinAge time.Duration
+		addDeletePhase  bool
+
+		wantFreedPerTier map[string]int64
+	}{
+		{
+			name:            "shorter retention",
+			newDeleteMinAge: 30 * day,
+
+			wantFreedPerTier: map[string]int64{"hot": 0, "warm": 300, "cold": 400, "delete": 0},
+		},
+		{
+			name:            "shorter retention, add delete phase",
+			newDeleteMinAge: 30 * day,
+			addDeletePhase:  true,
+
+			wantFreedPerTier: map[string]int64{"hot": 1000, "warm": 300, "cold": 400, "delete": 0},
+		},
+		{
+			name:            "longer retention",
+			newDeleteMinAge: 120 * day,
+
+			wantFreedPerTier: map[string]int64{"hot": 0, "warm": 0, "cold": 0, "delete": 0},
+		},
+		{
+			name:            "retention equal to age",
+			newDeleteMinAge: 20 * day,
+
+			wantFreedPerTier: map[string]int64{"hot": 0, "warm": 500, "cold": 400, "delete": 0},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			impact := calcRetentionImpact(deleteMinAges, indices, tc.newDeleteMinAge, tc.addDeletePhase)
+
+			require.True(t, impact["logs"].hasDeletePhase)
+			require.Equal(t, 90*day, impact["logs"].deleteMinAge)
+			require.False(t, impact["metrics"].hasDeletePhase)
+
+			require.Equal(t, 2, impact["logs"].phases["warm"].indices)
+			require.Equal(t, int64(500), impact["logs"].phases["warm"].totalSize)
+
+			gotFreedPerTier := make(map[string]int64)
+			for tier, tierRetention := range impact.PerTier() {
+				gotFreedPerTier[tier] = tierRetention.freedSize
+			}
+
+			require.Equal(t, tc.wantFreedPerTier, gotFreedPerTier)
+		})
+	}
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"fmt"
-	"strings"
+	"io"
+	"strconv"
 
+	"github.com/docker/go-units"
 	"github.com/urfave/cli/v3"
 )
 
@@ -17,36 +19,69 @@ func downscale(ctx context.Context, cmd *cli.Command) error {
 	headroomPercent := cmd.Float64("headroom-pct")
 	recommendZoneChange := cmd.Bool("recommend-zone-change")
 	exitCode := cmd.Bool("exit-code")
+	watchInterval := cmd.Duration("watch")
 
-	if !isRegionValid(region) {
-		return fmt.Errorf("region %q is not a known Elastic Cloud region", region)
+	if exitCode && watchInterval > 0 {
+		return fmt.Errorf("exit-code is not supported together with watch")
 	}
 
-	regionParts := strings.Split(region, "-")
-	if len(regionParts) != 2 {
-		return fmt.Errorf(`invalid region, expected format "<provider>-<region>", e.g. "azure-westeurope"`)
+	deploymentURL, err := deploymentURLWithCredentials(deployment, region, username, password)
+	if err != nil {
+		return err
 	}
 
-	provider := regionParts[0]
-	providerRegion := regionParts[1]
+	allocations, err := getAllocationInformation(deploymentURL)
+	if err != nil {
+		return err
+	}
 
-	tierDiskSizes, err := getTierSizes(region, profile)
+	deploymentTemplates, err := loadDeploymentTemplates(ctx, cmd.ErrWriter, region, templateSourceFromCommand(cmd))
 	if err != nil {
 		return err
 	}
 
-	verbosef(cmd, "%s", tierDiskSizes)
+	profile, err = selectProfile(cmd.Writer, profile, matchProfiles(allocations, deploymentTemplates))
+	if err != nil {
+		return err
+	}
 
-	usernamePassword := ""
-	if username != "" && password != "" {
-		usernamePassword = fmt.Sprintf("%s:%s@", username, password)
+	var tierDiskSizes TierSizes
+	for _, deploymentTemplate := range deploymentTemplates {
+		if deploymentTemplate.ID == profile {
+			tierDiskSizes = tierSizesFromTemplate(deploymentTemplate)
+		}
 	}
 
-	deploymentURL := fmt.Sprintf("https://%s%s.es.%s.%s.elastic-cloud.com", usernamePassword, deployment, providerRegion, provider)
+	if tierDiskSizes == nil {
+		return fmt.Errorf("profile %q is not available in region %q", profile, region)
+	}
 
-	allocations, err := getAllocationInformation(deploymentURL)
-	if err != nil {
-		return err
+	verbosef(cmd, "%s", tierDiskSizes)
+
+	if watchInterval > 0 {
+		var previous map[Tier]tierDisk
+		return watch(ctx, cmd.Writer, watchInterval, func(ctx context.Context, w io.Writer) error {
+			allocations, err := getAllocationInformation(deploymentURL)
+			if err != nil {
+				return err
+			}
+
+			recommendations := calcDownscaleRecommendation(allocations, tierDiskSizes, headroomPercent, recommendZoneChange)
+			fmt.Fprintf(w, "%s", recommendations)
+
+			current, err := tierDiskUsage(allocations)
+			if err != nil {
+				return err
+			}
+
+			err = renderTierUsage(w, current, previous)
+			if err != nil {
+				return err
+			}
+
+			previous = current
+			return nil
+		})
 	}
 
 	recommendations := calcDownscaleRecommendation(allocations, tierDiskSizes, headroomPercent, recommendZoneChange)
@@ -60,6 +95,47 @@ func downscale(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+// renderTierUsage writes the disk usage per tier as table to w. If the disk
+// usage of the previous refresh is given, the change of the disk usage since
+// the previous refresh is shown as well.
+func renderTierUsage(w io.Writer, current map[Tier]tierDisk, previous map[Tier]tierDisk) error {
+	data := make([][]string, 0, len(current))
+	for tier, disk := range mapOrderedByKey(current) {
+		var usedPct float64
+		if disk.total > 0 {
+			usedPct = 100.0 / disk.total * disk.used
+		}
+
+		change := "-"
+		if prev, ok := previous[tier]; ok {
+			change = formatSizeDelta(disk.used - prev.used)
+		}
+
+		data = append(data, []string{string(tier), strconv.Itoa(disk.nodes), units.BytesSize(disk.used), change, units.BytesSize(disk.total), fmt.Sprintf("%.1f%%", usedPct)})
+	}
+
+	table := newTable(w, "")
+	table.Header([]string{
+		"Tier", "Nodes", "Disk Used", "Change", "Disk Total", "Used %",
+	})
+	err := table.Bulk(data)
+	if err != nil {
+		return err
+	}
+
+	return table.Render()
+}
+
+// formatSizeDelta returns the difference of two sizes in bytes as human
+// readable string with sign.
+func formatSizeDelta(delta float64) string {
+	if delta < 0 {
+		return "-" + units.BytesSize(-delta)
+	}
+
+	return "+" + units.BytesSize(delta)
+}
+
 func verbosef(cmd *cli.Command, format string, args ...any) {
 	if cmd.Bool("verbose") {
 		fmt.Fprintf(cmd.Writer, format, args...)
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/ilm/getlifecycle"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/bytes"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/catshardcolumn"
+	"github.com/urfave/cli/v3"
+)
+
+// errBrowserQuit is returned by the levels of the ilmBrowser, if the user
+// quits the browser.
+var errBrowserQuit = errors.New("quit")
+
+// errBrowserBack is returned by the levels of the ilmBrowser, if the user
+// navigates back to the previous level.
+var errBrowserBack = errors.New("back")
+
+// ilmBrowser is an interactive, line based browser for the ILM state of the
+// indices. The indices are grouped by policy and phase, the user navigates by
+// entering the number of an entry or one of the commands shown in the prompt.
+type ilmBrowser struct {
+	client     *elasticsearch.TypedClient
+	filter     indexFilter
+	deployment string
+	region     string
+	maxFillPct float64
+
+	in  *bufio.Scanner
+	out io.Writer
+
+	indices  []indexDetails
+	policies getlifecycle.Response
+}
+
+func ilmBrowse(ctx context.Context, cmd *cli.Command) error {
+	filter, err := newIndexFilter(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := newTypedClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	b := &ilmBrowser{
+		client:     client,
+		filter:     filter,
+		deployment: cmd.String("deployment"),
+		region:     cmd.String("region"),
+		maxFillPct: cmd.Float64("max-fill-pct"),
+		in:         bufio.NewScanner(cmd.Reader),
+		out:        cmd.Writer,
+	}
+
+	err = b.refresh(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = b.browsePolicies(ctx)
+	if errors.Is(err, errBrowserQuit) {
+		return nil
+	}
+
+	return err
+}
+
+// refresh fetches the ILM state of the indices and the policies.
+func (b *ilmBrowser) refresh(ctx context.Context) error {
+	indices, err := getIndexDetails(ctx, b.client, "_all", b.filter)
+	if err != nil {
+		return err
+	}
+
+	sortIndexDetails(indices, true, nil)
+
+	policies, err := b.client.Ilm.GetLifecycle().Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	b.indices = indices
+	b.policies = policies
+
+	return nil
+}
+
+// prompt shows the message and returns the trimmed input of the user. If the
+// input is closed, errBrowserQuit is returned.
+func (b *ilmBrowser) prompt(message string) (string, error) {
+	fmt.Fprintf(b.out, "\n%s> ", message)
+
+	if !b.in.Scan() {
+		fmt.Fprintln(b.out)
+		if b.in.Err() != nil {
+			return "", b.in.Err()
+		}
+
+		return "", errBrowserQuit
+	}
+
+	return strings.TrimSpace(b.in.Text()), nil
+}
+
+// confirm asks the user to confirm the question with yes.
+func (b *ilmBrowser) confirm(question string) (bool, error) {
+	answer, err := b.prompt(question + " [y/N]")
+	if err != nil {
+		return false, err
+	}
+
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
+
+// navigate handles the commands, which are available on all levels.
+func (b *ilmBrowser) navigate(ctx context.Context, input string) (handled bool, err error) {
+	switch input {
+	case "q":
+		return true, errBrowserQuit
+	case "b":
+		return true, errBrowserBack
+	case "r":
+		return true, b.refresh(ctx)
+	}
+
+	return false, nil
+}
+
+// parseSelection returns the zero based index of the entry selected by the
+// one based number entered by the user.
+func parseSelection(input string, entries int) (int, error) {
+	n, err := strconv.Atoi(input)
+	if err != nil || n < 1 || n > entries {
+		return 0, fmt.Errorf("invalid selection %q, enter a number between 1 and %d or one of the commands", input, entries)
+	}
+
+	return n - 1, nil
+}
+
+// browseGroups shows the groups as numbered table and returns the key of the
+// group selected by the user.
+func (b *ilmBrowser) browseGroups(ctx context.Context, title string, groupBy string, indices func() []indexDetails, promptMessage string) (string, error) {
+	for {
+		groups := groupIndexDetails(indices(), groupBy)
+
+		fmt.Fprintf(b.out, "\n%s\n", title)
+		err := renderColumns(b.out, "", append([]column[indexGroup]{numberColumn(groups, func(g indexGroup) string { return g.key })}, indexGroupColumns(groupBy)...), groups)
+		if err != nil {
+			return "", err
+		}
+
+		input, err := b.prompt(promptMessage)
+		if err != nil {
+			return "", err
+		}
+
+		handled, err := b.navigate(ctx, input)
+		if err != nil {
+			return "", err
+		}
+
+		if handled {
+			continue
+		}
+
+		selected, err := parseSelection(input, len(groups))
+		if err != nil {
+			fmt.Fprintln(b.out, err)
+			continue
+		}
+
+		return groups[selected].key, nil
+	}
+}
+
+// numberColumn returns a column with the one based position of the rows,
+// which are identified by key.
+func numberColumn[T any](rows []T, key func(T) string) column[T] {
+	positions := make(map[string]int, len(rows))
+	for i, row := range rows {
+		positions[key(row)] = i + 1
+	}
+
+	return column[T]{
+		header: "#", key: "number",
+		value: func(row T) string { return strconv.Itoa(positions[key(row)]) },
+		raw:   func(row T) any { return positions[key(row)] },
+	}
+}
+
+func (b *ilmBrowser) browsePolicies(ctx context.Context) error {
+	for {
+		policy, err := b.browseGroups(ctx, "ILM policies:", "policy", func() []indexDetails { return b.indices }, "policy # (r: refresh, q: quit)")
+		if errors.Is(err, errBrowserBack) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = b.browsePhases(ctx, policy)
+		if err != nil && !errors.Is(err, errBrowserBack) {
+			return err
+		}
+	}
+}
+
+func (b *ilmBrowser) browsePhases(ctx context.Context, policy string) error {
+	policyIndices := func() []indexDetails {
+		return slices.DeleteFunc(slices.Clone(b.indices), func(index indexDetails) bool { return index.policy != policy })
+	}
+
+	for {
+		phase, err := b.browseGroups(ctx, fmt.Sprintf("Phases of policy %q:", policy), "phase", policyIndices, "phase # (b: back, r: refresh, q: quit)")
+		if err != nil {
+			return err
+		}
+
+		err = b.browseIndices(ctx, policy, phase)
+		if err != nil && !errors.Is(err, errBrowserBack) {
+			return err
+		}
+	}
+}
+
+func (b *ilmBrowser) browseIndices(ctx context.Context, policy string, phase string) error {
+	for {
+		indices := slices.DeleteFunc(slices.Clone(b.indices), func(index indexDetails) bool {
+			return index.policy != policy || index.phase != phase
+		})
+
+		columns, err := selectIndexColumns(defaultIndexColumns)
+		if err != nil {
+			return err
+		}
+
+		number := indexColumn{column: numberColumn(indices, func(i indexDetails) string { return i.name })}
+
+		fmt.Fprintf(b.out, "\nIndices of policy %q in phase %q:\n", policy, phase)
+		err = renderIndexColumns(b.out, "", append([]indexColumn{number}, columns...), indices)
+		if err != nil {
+			return err
+		}
+
+		input, err := b.prompt("index # (b: back, r: refresh, q: quit)")
+		if err != nil {
+			return err
+		}
+
+		handled, err := b.navigate(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		if handled {
+			continue
+		}
+
+		selected, err := parseSelection(input, len(indices))
+		if err != nil {
+			fmt.Fprintln(b.out, err)
+			continue
+		}
+
+		err = b.browseIndex(ctx, indices[selected])
+		if err != nil && !errors.Is(err, errBrowserBack) {
+			return err
+		}
+	}
+}
+
+func (b *ilmBrowser) browseIndex(ctx context.Context, index indexDetails) error {
+	err := b.showIndex(ctx, index)
+	if err != nil {
+		return err
+	}
+
+	for {
+		input, err := b.prompt("m <phase>: move, t: retry, x: remove policy (b: back, q: quit)")
+		if err != nil {
+			return err
+		}
+
+		command, arg, _ := strings.Cut(input, " ")
+		switch command {
+		case "q":
+			return errBrowserQuit
+		case "b":
+			return errBrowserBack
+		case "m":
+			err = b.move(ctx, index, strings.TrimSpace(arg))
+		case "t":
+			err = b.retry(ctx, index)
+		case "x":
+			err = b.removePolicy(ctx, index)
+		default:
+			fmt.Fprintf(b.out, "unknown command %q\n", input)
+			continue
+		}
+
+		if err != nil {
+			if errors.Is(err, errBrowserQuit) {
+				return err
+			}
+
+			fmt.Fprintln(b.out, err)
+			continue
+		}
+
+		// The ILM state of the index has changed, go back to the refreshed
+		// list of indices.
+		return b.refresh(ctx)
+	}
+}
+
+// showIndex shows the explain output, the policy definition and the shard
+// placement of the index.
+func (b *ilmBrowser) showIndex(ctx context.Context, index indexDetails) error {
+	explain, err := b.client.Ilm.ExplainLifecycle(index.name).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.MarshalIndent(explain.Indices[index.name], "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(b.out, "\nILM explain of index %q:\n%s\n", index.name, body)
+
+	if policy, ok := b.policies[index.policy]; ok {
+		body, err = marshalPolicy(policy.Policy, "json")
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(b.out, "\nPolicy %q:\n%s\n", index.policy, body)
+	}
+
+	shards, err := b.client.Cat.Shards().Index(index.name).H(catshardcolumn.Shard, catshardcolumn.Prirep, catshardcolumn.State, catshardcolumn.Node, catshardcolumn.Store).Bytes(bytes.B).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	data := make([][]string, 0, len(shards))
+	for _, shard := range shards {
+		store := deref(shard.Store)
+		if size, err := strconv.ParseFloat(store, 64); err == nil {
+			store = units.BytesSize(size)
+		}
+
+		data = append(data, []string{deref(shard.Shard), deref(shard.Prirep), deref(shard.State), deref(shard.Node), store})
+	}
+
+	fmt.Fprintf(b.out, "\nShards of index %q:\n", index.name)
+	table := newTable(b.out, "")
+	table.Header([]string{
+		"Shard", "Pri/Rep", "State", "Node", "Size",
+	})
+	err = table.Bulk(data)
+	if err != nil {
+		return err
+	}
+
+	return table.Render()
+}
+
+func (b *ilmBrowser) move(ctx context.Context, index indexDetails, phase string) error {
+	if !slices.Contains(ilmPhases, phase) {
+		return fmt.Errorf("invalid target phase %q, use one of %v", phase, ilmPhases)
+	}
+
+	target := moveTarget{phase: phase}
+
+	// The state of the index is confirmed separately below instead of
+	// skipping the index like ilm move without --force.
+	rejected, reason, err := checkMoveCandidate(index, target, b.policies, true)
+	if err != nil {
+		return err
+	}
+
+	if rejected != nil {
+		return errors.New(reason)
+	}
+
+	if !isPhaseComplete(index) {
+		ok, err := b.confirm(fmt.Sprintf(`index %q is not in "complete" state (action: %q, step: %q) in its phase, move anyway?`, index.name, index.action, index.step))
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	if phase != "delete" && phase != "frozen" {
+		check, err := moveCapacityCheck(ctx, b.client, []indexDetails{index}, b.policies, phase, b.maxFillPct)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(b.out, "\nCapacity check:\n%s\n\n", check)
+
+		if !check.Fits() {
+			return fmt.Errorf("data to move does not fit into tier %q, use \"ilm move --force\" to move anyway", phase)
+		}
+	}
+
+	if phase == "delete" {
+		input, err := b.prompt(fmt.Sprintf("moving %q to the delete phase deletes its data irreversibly, enter the index name to confirm", index.name))
+		if err != nil {
+			return err
+		}
+
+		if input != index.name {
+			fmt.Fprintln(b.out, "move canceled")
+			return nil
+		}
+	} else {
+		ok, err := b.confirm(fmt.Sprintf("move %q (phase: %q, action: %q, step: %q) to %s?", index.name, index.phase, index.action, index.step, target))
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	journalFilename := defaultJournalFilename(b.deployment, time.Now())
+	err = writeMoveJournal(journalFilename, b.deployment, b.region, []indexDetails{index}, target)
+	if err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+
+	err = moveToStep(ctx, b.client, index, target)
+	if err != nil {
+		return fmt.Errorf("move of %q failed: %w", index.name, err)
+	}
+
+	fmt.Fprintf(b.out, "moved %q to %s, journal written to %s\n", index.name, target, journalFilename)
+	return nil
+}
+
+func (b *ilmBrowser) retry(ctx context.Context, index indexDetails) error {
+	ok, err := b.confirm(fmt.Sprintf("retry the failed step of %q?", index.name))
+	if err != nil || !ok {
+		return err
+	}
+
+	resp, err := b.client.Ilm.Retry(index.name).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("retry of %q failed: %w", index.name, err)
+	}
+
+	if !resp.Acknowledged {
+		return fmt.Errorf("retry of %q has not been acknowledged", index.name)
+	}
+
+	fmt.Fprintf(b.out, "retried %q\n", index.name)
+	return nil
+}
+
+func (b *ilmBrowser) removePolicy(ctx context.Context, index indexDetails) error {
+	ok, err := b.confirm(fmt.Sprintf("remove policy %q from %q?", index.policy, index.name))
+	if err != nil || !ok {
+		return err
+	}
+
+	resp, err := b.client.Ilm.RemovePolicy(index.name).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if resp.HasFailures {
+		return fmt.Errorf("remove policy operation for %q failed", index.name)
+	}
+
+	fmt.Fprintf(b.out, "removed policy from %q\n", index.name)
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/ilm/getlifecycle"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBrowser(input string, indices []indexDetails) (*ilmBrowser, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+
+	return &ilmBrowser{
+		in:      bufio.NewScanner(strings.NewReader(input)),
+		out:     out,
+		indices: indices,
+	}, out
+}
+
+func Test_ilmBrowser_browseGroups(t *testing.T) {
+	indices := []indexDetails{
+		{name: "logs-1", policy: "logs", phase: "hot"},
+		{name: "metrics-1", policy: "metrics", phase: "warm"},
+	}
+
+	b, out := newTestBrowser("invalid\n3\n2\n", indices)
+
+	policy, err := b.browseGroups(context.Background(), "ILM policies:", "policy", func() []indexDetails { return b.indices }, "policy #")
+	require.NoError(t, err)
+	require.Equal(t, "metrics", policy)
+	require.Contains(t, out.String(), `invalid selection "invalid"`)
+	require.Contains(t, out.String(), `invalid selection "3"`)
+
+	b, _ = newTestBrowser("b\n", indices)
+	_, err = b.browseGroups(context.Background(), "ILM policies:", "policy", func() []indexDetails { return b.indices }, "policy #")
+	require.ErrorIs(t, err, errBrowserBack)
+
+	b, _ = newTestBrowser("", indices)
+	_, err = b.browseGroups(context.Background(), "ILM policies:", "policy", func() []indexDetails { return b.indices }, "policy #")
+	require.ErrorIs(t, err, errBrowserQuit)
+}
+
+func Test_ilmBrowser_confirm(t *testing.T) {
+	tests := []struct {
+		input string
+
+		want bool
+	}{
+		{input: "y\n", want: true},
+		{input: "YES\n", want: true},
+		{input: "\n", want: false},
+		{input: "n\n", want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(strings.TrimSpace(tc.input), func(t *testing.T) {
+			b, _ := newTestBrowser(tc.input, nil)
+
+			ok, err := b.confirm("continue?")
+			require.NoError(t, err)
+			require.Equal(t, tc.want, ok)
+		})
+	}
+}
+
+func Test_ilmBrowser_move_invalidPhase(t *testing.T) {
+	b, _ := newTestBrowser("", nil)
+
+	err := b.move(context.Background(), indexDetails{name: "logs-1", phase: "warm"}, "lukewarm")
+	require.Error(t, err)
+
+	err = b.move(context.Background(), indexDetails{name: "logs-1", phase: "warm"}, "warm")
+	require.Error(t, err)
+}
+
+func Test_ilmBrowser_move_rejected(t *testing.T) {
+	policies := getlifecycle.Response{
+		"logs": types.Lifecycle{Policy: types.IlmPolicy{Phases: types.Phases{Hot: &types.Phase{}, Warm: &types.Phase{}}}},
+	}
+
+	tests := []struct {
+		name  string
+		index indexDetails
+		input string
+
+		wantErr    string
+		wantOutput string
+	}{
+		{
+			name:  "write index",
+			index: indexDetails{name: ".ds-logs-2", policy: "logs", phase: "hot", action: "rollover", step: "check-rollover-ready", dataStream: "logs", writeIndex: true},
+
+			wantErr: `index ".ds-logs-2" is the write index of data stream "logs"`,
+		},
+		{
+			name:  "phase not defined",
+			index: indexDetails{name: "logs-1", policy: "logs", phase: "warm", action: "complete", step: "complete"},
+
+			wantErr: `target phase "cold" is not defined in policy "logs" used by index "logs-1"`,
+		},
+		{
+			name:  "not complete, canceled",
+			index: indexDetails{name: "logs-1", policy: "logs", phase: "hot", action: "rollover", step: "check-rollover-ready"},
+			input: "n\n",
+
+			wantOutput: `index "logs-1" is not in "complete" state (action: "rollover", step: "check-rollover-ready") in its phase, move anyway?`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, out := newTestBrowser(tc.input, nil)
+			b.policies = policies
+
+			phase := "warm"
+			if tc.index.phase == "warm" {
+				phase = "cold"
+			}
+
+			err := b.move(context.Background(), tc.index, phase)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Contains(t, out.String(), tc.wantOutput)
+		})
+	}
+}
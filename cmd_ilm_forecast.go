@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/urfave/cli/v3"
+)
+
+// forecastRow is a row of the forecast table, which contains the tierFlow of a
+// tier within a period.
+type forecastRow struct {
+	period time.Time
+	tier   string
+	flow   tierFlow
+}
+
+// forecastColumns returns the columns of the forecast table. Sizes are given
+// in bytes in the machine readable formats.
+func forecastColumns(interval string) []column[forecastRow] {
+	return []column[forecastRow]{
+		{
+			header: "Period", key: interval,
+			value: func(r forecastRow) string {
+				if interval == "week" {
+					return "week of " + r.period.Format(time.DateOnly)
+				}
+
+				return r.period.Format(time.DateOnly)
+			},
+			raw: func(r forecastRow) any { return r.period.Format(time.DateOnly) },
+		},
+		{
+			header: "Tier", key: "tier",
+			value: func(r forecastRow) string { return r.tier },
+			raw:   func(r forecastRow) any { return r.tier },
+		},
+		{
+			header: "Indices In", key: "indices_in",
+			value: func(r forecastRow) string { return strconv.Itoa(r.flow.indicesIn) },
+			raw:   func(r forecastRow) any { return r.flow.indicesIn },
+		},
+		{
+			header: "Data In", key: "size_in_bytes",
+			value: func(r forecastRow) string { return units.BytesSize(float64(r.flow.sizeIn)) },
+			raw:   func(r forecastRow) any { return r.flow.sizeIn },
+		},
+		{
+			header: "Indices Out", key: "indices_out",
+			value: func(r forecastRow) string { return strconv.Itoa(r.flow.indicesOut) },
+			raw:   func(r forecastRow) any { return r.flow.indicesOut },
+		},
+		{
+			header: "Data Out", key: "size_out_bytes",
+			value: func(r forecastRow) string { return units.BytesSize(float64(r.flow.sizeOut)) },
+			raw:   func(r forecastRow) any { return r.flow.sizeOut },
+		},
+		{
+			header: "Net Change", key: "net_change_bytes",
+			value: func(r forecastRow) string { return formatSizeDelta(float64(r.flow.sizeIn - r.flow.sizeOut)) },
+			raw:   func(r forecastRow) any { return r.flow.sizeIn - r.flow.sizeOut },
+		},
+	}
+}
+
+// phaseTransitionColumns are the columns of the individual phase transitions.
+var phaseTransitionColumns = []column[phaseTransition]{
+	{
+		header: "Date", key: "date",
+		value: func(t phaseTransition) string { return t.at.UTC().Format(time.DateTime) },
+		raw:   func(t phaseTransition) any { return t.at.UTC().Format(time.RFC3339) },
+	},
+	{
+		header: "Index", key: "index",
+		value: func(t phaseTransition) string { return t.index },
+		raw:   func(t phaseTransition) any { return t.index },
+	},
+	{
+		header: "Policy", key: "policy",
+		value: func(t phaseTransition) string { return t.policy },
+		raw:   func(t phaseTransition) any { return t.policy },
+	},
+	{
+		header: "From", key: "from",
+		value: func(t phaseTransition) string { return t.from },
+		raw:   func(t phaseTransition) any { return t.from },
+	},
+	{
+		header: "To", key: "to",
+		value: func(t phaseTransition) string { return t.to },
+		raw:   func(t phaseTransition) any { return t.to },
+	},
+	{
+		header: "Size", key: "size_bytes",
+		value: func(t phaseTransition) string { return units.BytesSize(float64(t.enteringSize)) },
+		raw:   func(t phaseTransition) any { return t.enteringSize },
+	},
+}
+
+func ilmForecast(ctx context.Context, cmd *cli.Command) error {
+	days := cmd.Int("days")
+	interval := cmd.String("interval")
+	withIndices := cmd.Bool("with-indices")
+	format := cmd.String("format")
+
+	if days <= 0 {
+		return fmt.Errorf("days must be greater than 0")
+	}
+
+	if !slices.Contains([]string{"day", "week"}, interval) {
+		return fmt.Errorf("interval %q is not supported, use day or week", interval)
+	}
+
+	err := validateOutputFormat(format)
+	if err != nil {
+		return err
+	}
+
+	if withIndices && slices.Contains([]string{"json", "ndjson", "csv"}, format) {
+		return fmt.Errorf("with-indices is only supported for the formats table, compact and markdown")
+	}
+
+	filter, err := newIndexFilter(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := newTypedClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	indices, err := getIndexDetails(ctx, client, "_all", filter)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Ilm.GetLifecycle().Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	policies := make(map[string]types.IlmPolicy, len(res))
+	for name, policy := range res {
+		policies[name] = policy.Policy
+	}
+
+	now := time.Now()
+	transitions, err := calcPhaseTransitions(indices, policies, now, time.Duration(days)*day)
+	if err != nil {
+		return err
+	}
+
+	if withIndices {
+		err = renderColumns(cmd.Writer, format, phaseTransitionColumns, transitions)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows := []forecastRow{}
+	for _, period := range calcForecast(transitions, interval) {
+		for _, tier := range sortedPhases(period.tiers) {
+			rows = append(rows, forecastRow{period: period.start, tier: tier, flow: period.tiers[tier]})
+		}
+	}
+
+	return renderColumns(cmd.Writer, format, forecastColumns(interval), rows)
+}
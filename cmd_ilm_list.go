@@ -4,14 +4,13 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
-	"os"
+	"io"
 	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/docker/go-units"
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/bytes"
@@ -22,158 +21,201 @@ import (
 )
 
 func ilmList(ctx context.Context, cmd *cli.Command) error {
-	deployment := cmd.String("deployment")
-	region := cmd.String("region")
-	username := cmd.String("username")
-	password := cmd.String("password")
-
-	action := cmd.String("action")
-	phase := cmd.String("phase")
-	ilmPolicy := cmd.String("ilm-policy")
-	sortColumns := cmd.StringSlice("sort")
-	minPriSizeStr := cmd.String("min-pri-size")
-	minTotalSizeStr := cmd.String("min-total-size")
-	minAge := time.Duration(cmd.Int("min-age-days")) * 24 * time.Hour
 	format := cmd.String("format")
 
-	if !isRegionValid(region) {
-		return fmt.Errorf("region %q is not a known Elastic Cloud region", region)
-	}
+	groupBy := cmd.String("group-by")
+	withIndices := cmd.Bool("with-indices")
+	watchInterval := cmd.Duration("watch")
 
-	regionParts := strings.Split(region, "-")
-	if len(regionParts) != 2 {
-		return fmt.Errorf(`invalid region, expected format "<provider>-<region>", e.g. "azure-westeurope"`)
+	err := validateOutputFormat(format)
+	if err != nil {
+		return err
 	}
 
-	allowedSortColumns := []string{"age", "pri-size", "total-size"}
-	for _, sortColumn := range sortColumns {
-		if !slices.Contains(allowedSortColumns, sortColumn) {
-			return fmt.Errorf("column %q is not allowed for sorting, use one of %v", sortColumn, allowedSortColumns)
-		}
+	if watchInterval > 0 && slices.Contains([]string{"json", "ndjson", "csv"}, format) {
+		return fmt.Errorf("watch is only supported for the formats table, compact and markdown")
 	}
 
-	var err error
-	var minPriSize int64
-	if minPriSizeStr != "" {
-		minPriSize, err = units.FromHumanSize(minPriSizeStr)
-		if err != nil {
-			return fmt.Errorf("failed to parse minimum size: %w", err)
-		}
+	err = validateGroupBy(groupBy)
+	if err != nil {
+		return err
 	}
 
-	var minTotalSize int64
-	if minTotalSizeStr != "" {
-		minTotalSize, err = units.FromHumanSize(minTotalSizeStr)
-		if err != nil {
-			return fmt.Errorf("failed to parse minimum size: %w", err)
-		}
+	if groupBy != "" && withIndices && slices.Contains([]string{"json", "ndjson", "csv"}, format) {
+		return fmt.Errorf("with-indices together with group-by is only supported for the formats table, compact and markdown")
 	}
 
-	provider := regionParts[0]
-	providerRegion := regionParts[1]
+	columns, err := selectIndexColumns(cmd.StringSlice("columns"))
+	if err != nil {
+		return err
+	}
 
-	deploymentURL := fmt.Sprintf("https://%s.es.%s.%s.elastic-cloud.com", deployment, providerRegion, provider)
+	sorts, err := parseIndexSorts(cmd.StringSlice("sort"))
+	if err != nil {
+		return err
+	}
 
-	client, err := elasticsearch.NewTypedClient(elasticsearch.Config{
-		Addresses: []string{
-			deploymentURL,
-		},
-		Username: username,
-		Password: password,
-	})
+	filter, err := newIndexFilter(cmd)
 	if err != nil {
 		return err
 	}
 
-	indices, err := client.Cat.Indices().H("index", "store.size", "pri.store.size").Bytes(bytes.B).Do(ctx)
+	client, err := newTypedClient(cmd)
 	if err != nil {
 		return err
 	}
 
-	totalSizes := make(map[string]int64, len(indices))
-	priSizes := make(map[string]int64, len(indices))
+	extraColumns := slices.Clone(columns)
+	for _, s := range sorts {
+		extraColumns = append(extraColumns, s.column)
+	}
 
-	for _, index := range indices {
-		totalSize, err := strconv.ParseInt(*index.StoreSize, 10, 64)
+	listIndices := func(ctx context.Context) ([]indexDetails, error) {
+		indexILM, err := getIndexDetails(ctx, client, "_all", filter)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		totalSizes[*index.Index] = totalSize
-
-		priSize, err := strconv.ParseInt(*index.PriStoreSize, 10, 64)
+		err = addIndexDetailsExtras(ctx, client, indexILM, extraColumns)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		priSizes[*index.Index] = priSize
-	}
+		sortIndexDetails(indexILM, len(filter.phases) != 1, sorts)
 
-	ilms, err := client.Ilm.ExplainLifecycle("_all").OnlyManaged(true).Do(ctx)
-	if err != nil {
-		return err
+		return indexILM, nil
 	}
 
-	type indexDetails struct {
-		name      string
-		phase     string
-		action    string
-		step      string
-		policy    string
-		age       time.Duration
-		priSize   int64
-		totalSize int64
+	render := func(w io.Writer, indexILM []indexDetails, columns []indexColumn) error {
+		if groupBy == "" || withIndices {
+			err := renderIndexColumns(w, format, columns, indexILM)
+			if err != nil {
+				return err
+			}
+		}
+
+		if groupBy != "" {
+			return renderColumns(w, format, indexGroupColumns(groupBy), groupIndexDetails(indexILM, groupBy))
+		}
+
+		return nil
 	}
 
-	indexILM := make([]indexDetails, 0, len(ilms.Indices))
+	if watchInterval == 0 {
+		indexILM, err := listIndices(ctx)
+		if err != nil {
+			return err
+		}
+
+		return render(cmd.Writer, indexILM, columns)
+	}
 
-	for index, ilm := range ilms.Indices {
-		managed, ok := ilm.(*types.LifecycleExplainManaged)
-		if !ok {
-			continue
+	var previous map[string]indexDetails
+	return watch(ctx, cmd.Writer, watchInterval, func(ctx context.Context, w io.Writer) error {
+		indexILM, err := listIndices(ctx)
+		if err != nil {
+			return err
 		}
 
-		if action != "" && action != *managed.Action {
-			continue
+		changes, removed := diffIndexDetails(previous, indexILM)
+		previous = make(map[string]indexDetails, len(indexILM))
+		for _, index := range indexILM {
+			previous[index.name] = index
 		}
 
-		if phase != "" && phase != *managed.Phase {
-			continue
+		err = render(w, indexILM, append([]indexColumn{changesColumn(changes)}, columns...))
+		if err != nil {
+			return err
 		}
 
-		if ilmPolicy != "" && ilmPolicy != *managed.Policy {
-			continue
+		if len(removed) > 0 {
+			fmt.Fprintf(w, "Removed since last refresh: %s\n", strings.Join(removed, ", "))
 		}
 
-		priSize := priSizes[index]
+		return nil
+	})
+}
+
+// diffIndexDetails returns the changes of the indices since the previous
+// refresh by index name as well as the names of the removed indices. The
+// changes contain "new" for new indices and the names of the changed fields
+// (phase, action, step, size) otherwise. Without previous refresh, there are
+// no changes.
+func diffIndexDetails(previous map[string]indexDetails, indices []indexDetails) (changes map[string]string, removed []string) {
+	changes = make(map[string]string, len(indices))
+	if previous == nil {
+		return changes, nil
+	}
 
-		if priSize < minPriSize {
+	current := make(map[string]bool, len(indices))
+	for _, index := range indices {
+		current[index.name] = true
+
+		prev, ok := previous[index.name]
+		if !ok {
+			changes[index.name] = "new"
 			continue
 		}
 
-		totalSize := totalSizes[index]
+		var changed []string
+		if prev.phase != index.phase {
+			changed = append(changed, "phase")
+		}
 
-		if totalSize < minTotalSize {
-			continue
+		if prev.action != index.action {
+			changed = append(changed, "action")
 		}
 
-		age, err := parseESDuration(managed.Age)
-		if err != nil {
-			return err
+		if prev.step != index.step {
+			changed = append(changed, "step")
 		}
 
-		if age < minAge {
-			continue
+		if prev.priSize != index.priSize || prev.totalSize != index.totalSize {
+			changed = append(changed, "size")
+		}
+
+		changes[index.name] = strings.Join(changed, ",")
+	}
+
+	for name := range previous {
+		if !current[name] {
+			removed = append(removed, name)
 		}
+	}
+
+	sort.Strings(removed)
+
+	return changes, removed
+}
+
+// changesColumn returns the column showing the changes of the indices since
+// the previous refresh in watch mode.
+func changesColumn(changes map[string]string) indexColumn {
+	return indexColumn{
+		name: "changes",
+		column: column[indexDetails]{
+			header: "Changes", key: "changes",
+			value: func(i indexDetails) string {
+				if changes[i.name] == "" {
+					return ""
+				}
 
-		indexILM = append(indexILM, indexDetails{name: index, phase: *managed.Phase, action: *managed.Action, step: *managed.Step, policy: *managed.Policy, age: age, priSize: priSize, totalSize: totalSize})
+				return "* " + changes[i.name]
+			},
+			raw: func(i indexDetails) any { return changes[i.name] },
+		},
 	}
+}
 
+// sortIndexDetails sorts the indices by the given sort criteria. If byPhase
+// is true, the indices are grouped by phase first. Indices with equal values
+// are sorted by name.
+func sortIndexDetails(indexILM []indexDetails, byPhase bool, sorts []indexSort) {
 	// Apply the sort criteria as less functions controlled by:
 	// - might the result contain multiple phases
-	// - provided sort columns, applied in order
+	// - provided sort criteria, applied in order
 	lessFuncs := []func(i, j int) (final, less bool){}
-	if phase == "" {
+	if byPhase {
 		lessFuncs = append(lessFuncs, func(i, j int) (final bool, less bool) {
 			if indexILM[i].phase != indexILM[j].phase {
 				return true, phaseLess(indexILM[i].phase, indexILM[j].phase)
@@ -183,35 +225,19 @@ func ilmList(ctx context.Context, cmd *cli.Command) error {
 		})
 	}
 
-	for _, sortColumn := range sortColumns {
-		switch sortColumn {
-		case "age":
-			lessFuncs = append(lessFuncs, func(i, j int) (final bool, less bool) {
-				if indexILM[i].age != indexILM[j].age {
-					return true, indexILM[i].age > indexILM[j].age
-				}
-
-				return false, false
-			})
-
-		case "pri-size":
-			lessFuncs = append(lessFuncs, func(i, j int) (final bool, less bool) {
-				if indexILM[i].priSize != indexILM[j].priSize {
-					return true, indexILM[i].priSize > indexILM[j].priSize
+	for _, s := range sorts {
+		lessFuncs = append(lessFuncs, func(i, j int) (final bool, less bool) {
+			a, b := s.column.sortKey(indexILM[i]), s.column.sortKey(indexILM[j])
+			if a != b {
+				if s.ascending {
+					return true, a < b
 				}
 
-				return false, false
-			})
-
-		case "total-size":
-			lessFuncs = append(lessFuncs, func(i, j int) (final bool, less bool) {
-				if indexILM[i].totalSize != indexILM[j].totalSize {
-					return true, indexILM[i].totalSize > indexILM[j].totalSize
-				}
+				return true, a > b
+			}
 
-				return false, false
-			})
-		}
+			return false, false
+		})
 	}
 
 	// Sort indices by the lessFuncs.
@@ -225,12 +251,98 @@ func ilmList(ctx context.Context, cmd *cli.Command) error {
 
 		return indexILM[i].name < indexILM[j].name
 	})
+}
+
+// renderIndexDetails writes the indices in the given format to w. See
+// outputFormats for the supported formats.
+func renderIndexDetails(w io.Writer, format string, indexILM []indexDetails) error {
+	columns, err := selectIndexColumns(defaultIndexColumns)
+	if err != nil {
+		return err
+	}
+
+	return renderIndexColumns(w, format, columns, indexILM)
+}
+
+// indexStats contains the sizes, the document count and the shard count of an
+// index.
+type indexStats struct {
+	priSize       int64
+	totalSize     int64
+	docs          int64
+	priShards     int64
+	replicaShards int64
+}
+
+// getIndexStats returns the indexStats for all indices of the cluster.
+func getIndexStats(ctx context.Context, client *elasticsearch.TypedClient) (map[string]indexStats, error) {
+	indices, err := client.Cat.Indices().H("index", "store.size", "pri.store.size", "docs.count", "pri", "rep").Bytes(bytes.B).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]indexStats, len(indices))
+	for _, index := range indices {
+		var indexStat indexStats
+
+		indexStat.totalSize, err = strconv.ParseInt(*index.StoreSize, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		indexStat.priSize, err = strconv.ParseInt(*index.PriStoreSize, 10, 64)
+		if err != nil {
+			return nil, err
+		}
 
-	data := make([][]string, 0, len(indexILM))
-	for _, item := range indexILM {
-		data = append(data, []string{item.name, item.phase, item.action, item.step, item.policy, formatDuration(item.age), units.BytesSize(float64(item.priSize)), units.BytesSize(float64(item.totalSize))})
+		// The document count is not available for closed indices.
+		if deref(index.DocsCount) != "" {
+			indexStat.docs, err = strconv.ParseInt(*index.DocsCount, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		indexStat.priShards, err = strconv.ParseInt(deref(index.Pri), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		replicas, err := strconv.ParseInt(deref(index.Rep), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		indexStat.replicaShards = indexStat.priShards * replicas
+
+		stats[*index.Index] = indexStat
+	}
+
+	return stats, nil
+}
+
+// getIndexSizes returns the primary shard size and the total size (primary
+// shards and replicas) in bytes for all indices of the cluster.
+func getIndexSizes(ctx context.Context, client *elasticsearch.TypedClient) (priSizes map[string]int64, totalSizes map[string]int64, err error) {
+	stats, err := getIndexStats(ctx, client)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	totalSizes = make(map[string]int64, len(stats))
+	priSizes = make(map[string]int64, len(stats))
+
+	for index, stat := range stats {
+		totalSizes[index] = stat.totalSize
+		priSizes[index] = stat.priSize
+	}
+
+	return priSizes, totalSizes, nil
+}
+
+// newTable returns a table writing to w, rendered according to the given
+// format. Supported formats are table (default), compact and markdown.
+func newTable(w io.Writer, format string) *tablewriter.Table {
 	opts := []tablewriter.Option{
 		tablewriter.WithRenderer(
 			renderer.NewBlueprint(),
@@ -251,23 +363,15 @@ func ilmList(ctx context.Context, cmd *cli.Command) error {
 				),
 			),
 		}
+	case "markdown":
+		opts = []tablewriter.Option{
+			tablewriter.WithRenderer(
+				renderer.NewMarkdown(),
+			),
+		}
 	}
 
-	table := tablewriter.NewTable(os.Stdout, opts...)
-	table.Header([]string{
-		"Index", "Phase", "Action", "Step", "Policy", "Age", "Pri Size", "Total Size",
-	})
-	err = table.Bulk(data)
-	if err != nil {
-		return err
-	}
-
-	err = table.Render()
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return tablewriter.NewTable(w, opts...)
 }
 
 // parseESDuration parses the duration format of Elasticsearch to a Go duration.
@@ -330,6 +434,7 @@ var phaseOrder = map[string]int64{
 	"warm":   1,
 	"cold":   2,
 	"frozen": 3,
+	"delete": 4,
 }
 
 // phaseLess returns if phase a has the lower order than phase b.
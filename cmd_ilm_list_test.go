@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_diffIndexDetails(t *testing.T) {
+	previous := map[string]indexDetails{
+		"logs-1": {name: "logs-1", phase: "hot", action: "complete", step: "complete", priSize: 10, totalSize: 20},
+		"logs-2": {name: "logs-2", phase: "warm", action: "complete", step: "complete", priSize: 10, totalSize: 20},
+		"logs-3": {name: "logs-3", phase: "warm", action: "complete", step: "complete"},
+	}
+
+	indices := []indexDetails{
+		{name: "logs-1", phase: "warm", action: "allocate", step: "allocate", priSize: 10, totalSize: 20},
+		{name: "logs-2", phase: "warm", action: "complete", step: "complete", priSize: 10, totalSize: 20},
+		{name: "logs-4", phase: "hot", action: "rollover", step: "check-rollover-ready"},
+	}
+
+	changes, removed := diffIndexDetails(previous, indices)
+	require.Equal(t, map[string]string{
+		"logs-1": "phase,action,step",
+		"logs-2": "",
+		"logs-4": "new",
+	}, changes)
+	require.Equal(t, []string{"logs-3"}, removed)
+
+	changes, removed = diffIndexDetails(nil, indices)
+	require.Empty(t, changes)
+	require.Empty(t, removed)
+}
@@ -2,130 +2,739 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/ilm/getlifecycle"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/bytes"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/healthstatus"
 	"github.com/urfave/cli/v3"
 )
 
-func ilmMove(ctx context.Context, cmd *cli.Command) error {
-	deployment := cmd.String("deployment")
-	region := cmd.String("region")
-	username := cmd.String("username")
-	password := cmd.String("password")
+const (
+	settlePollInterval = 10 * time.Second
+	waitPollInterval   = 10 * time.Second
+)
+
+const (
+	moveSucceeded = "succeeded"
+	moveSkipped   = "skipped"
+	moveFailed    = "failed"
+)
+
+const (
+	waitStatePending  = "pending"
+	waitStateComplete = "complete"
+	waitStateDeleted  = "deleted"
+	waitStateFailed   = "failed"
+)
+
+// moveTarget contains the step key an index is moved to. Action and step are
+// optional, if not given, ILM moves the index to the first step of the phase
+// or action.
+type moveTarget struct {
+	phase  string
+	action string
+	step   string
+}
+
+func (t moveTarget) String() string {
+	str := fmt.Sprintf("phase %q", t.phase)
+	if t.action != "" {
+		str += fmt.Sprintf(", action %q", t.action)
+	}
+
+	if t.step != "" {
+		str += fmt.Sprintf(", step %q", t.step)
+	}
+
+	return str
+}
+
+// stepKey returns the step key for the MoveToStep API.
+func (t moveTarget) stepKey() *types.StepKey {
+	stepKey := &types.StepKey{
+		Phase: t.phase,
+	}
+
+	if t.action != "" {
+		stepKey.Action = &t.action
+	}
+
+	if t.step != "" {
+		stepKey.Name = &t.step
+	}
+
+	return stepKey
+}
+
+// ilmActionSteps contains the steps of the ILM actions, which can be used as
+// target step. The steps of the actions, which run many internal steps (e.g.
+// shrink or searchable_snapshot), are not listed, these actions can only be
+// re-run as a whole.
+var ilmActionSteps = map[string][]string{
+	"allocate":          {"allocate", "check-allocation"},
+	"delete":            {"wait-for-shard-history-leases", "cleanup-snapshot", "delete"},
+	"forcemerge":        {"check-not-write-index", "readonly", "forcemerge", "segment-count"},
+	"migrate":           {"migrate", "check-migration"},
+	"readonly":          {"check-not-write-index", "readonly"},
+	"rollover":          {"check-rollover-ready", "attempt-rollover", "wait-for-active-shards", "update-rollover-lifecycle-date", "set-indexing-complete"},
+	"set_priority":      {"set_priority"},
+	"wait_for_snapshot": {"wait-for-snapshot"},
+}
+
+// validateTargetStep checks, if the step is a step of the action.
+func validateTargetStep(action string, step string) error {
+	steps, ok := ilmActionSteps[action]
+	if !ok {
+		actions := slices.Sorted(maps.Keys(ilmActionSteps))
+		return fmt.Errorf("target-step is not supported for action %q, move to the action without --target-step instead, target-step is supported for the actions: %v", action, actions)
+	}
+
+	if !slices.Contains(steps, step) {
+		return fmt.Errorf("target-step %q is invalid for action %q, valid values are: %v", step, action, steps)
+	}
+
+	return nil
+}
+
+// moveResult contains the outcome of the move operation for a single index.
+type moveResult struct {
+	index   string
+	result  string
+	details string
+}
 
+func ilmMove(ctx context.Context, cmd *cli.Command) error {
 	dryRun := cmd.Bool("dry-run")
 	force := cmd.Bool("force")
 	indexPattern := cmd.String("index-pattern")
 	targetPhase := cmd.String("target-phase")
-
-	if !isRegionValid(region) {
-		return fmt.Errorf("region %q is not a known Elastic Cloud region", region)
+	target := moveTarget{
+		phase:  targetPhase,
+		action: cmd.String("target-action"),
+		step:   cmd.String("target-step"),
 	}
+	maxInFlight := int(cmd.Int("max-in-flight"))
+	batchSize := int(cmd.Int("batch-size"))
+	pause := cmd.Duration("pause")
+	settleTimeout := cmd.Duration("settle-timeout")
+	format := cmd.String("format")
+	maxFillPct := cmd.Float64("max-fill-pct")
+	wait := cmd.Bool("wait")
+	waitTimeout := cmd.Duration("timeout")
+	journalFilename := cmd.String("journal")
+	confirmDelete := cmd.Bool("i-know-this-deletes-data")
 
 	var dryRunPrefix string
 	if dryRun {
 		dryRunPrefix = "(DRY RUN) "
 	}
 
-	phases := []string{"hot", "warm", "cold", "frozen", "delete"}
-	if !slices.Contains(phases, targetPhase) {
-		return fmt.Errorf("target-phase %q is invalid, valid values are: %v", targetPhase, phases)
+	if !slices.Contains(ilmPhases, targetPhase) {
+		return fmt.Errorf("target-phase %q is invalid, valid values are: %v", targetPhase, ilmPhases)
 	}
 
-	regionParts := strings.Split(region, "-")
-	if len(regionParts) != 2 {
-		return fmt.Errorf(`invalid region, expected format "<provider>-<region>", e.g. "azure-westeurope"`)
+	if target.step != "" && target.action == "" {
+		return fmt.Errorf("target-step requires target-action to be given")
 	}
 
-	provider := regionParts[0]
-	providerRegion := regionParts[1]
+	if target.step != "" {
+		err := validateTargetStep(target.action, target.step)
+		if err != nil {
+			return err
+		}
+	}
 
-	deploymentURL := fmt.Sprintf("https://%s.es.%s.%s.elastic-cloud.com", deployment, providerRegion, provider)
+	if targetPhase == "delete" && !confirmDelete && !dryRun {
+		return fmt.Errorf("moving indices to the delete phase deletes their data irreversibly, confirm with --i-know-this-deletes-data")
+	}
 
-	client, err := elasticsearch.NewTypedClient(elasticsearch.Config{
-		Addresses: []string{
-			deploymentURL,
-		},
-		Username: username,
-		Password: password,
-	})
+	err := validateTableFormat(format)
+	if err != nil {
+		return err
+	}
+
+	if maxInFlight < 1 {
+		return fmt.Errorf("max-in-flight must be at least 1, got %d", maxInFlight)
+	}
+
+	filter, err := newIndexFilter(cmd)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.IsSet("index-pattern") && filter.isEmpty() {
+		return fmt.Errorf("at least --index-pattern or one of the filters is required to select the indices to move")
+	}
+
+	client, err := newTypedClient(cmd)
 	if err != nil {
 		return err
 	}
 
-	ilms, err := client.Ilm.ExplainLifecycle(indexPattern).OnlyManaged(true).Do(ctx)
+	indices, err := getIndexDetails(ctx, client, indexPattern, filter)
 	if err != nil {
 		return err
 	}
 
+	sortIndexDetails(indices, len(filter.phases) != 1, nil)
+
 	policies, err := client.Ilm.GetLifecycle().Do(ctx)
 	if err != nil {
 		return err
 	}
 
-	for _, ilm := range ilms.Indices {
-		managed, ok := ilm.(*types.LifecycleExplainManaged)
-		if !ok {
+	results := make([]moveResult, 0, len(indices))
+	candidates := make([]indexDetails, 0, len(indices))
+	for _, index := range indices {
+		rejected, reason, err := checkMoveCandidate(index, target, policies, force)
+		if err != nil {
+			return err
+		}
+
+		if rejected != nil {
+			if rejected.result == moveSkipped {
+				reason += ", skipping"
+			}
+
+			fmt.Fprintln(cmd.Writer, reason)
+			results = append(results, *rejected)
 			continue
 		}
 
-		if *managed.Phase == targetPhase {
-			fmt.Printf("index %q is already in phase %q, skipping\n", managed.Index, *managed.Phase)
+		candidates = append(candidates, index)
+	}
+
+	if len(candidates) == 0 {
+		return moveSummary(cmd, results)
+	}
+
+	fmt.Fprintf(cmd.Writer, "\n%sindices to move to %s:\n", dryRunPrefix, target)
+
+	err = renderIndexDetails(cmd.Writer, format, candidates)
+	if err != nil {
+		return err
+	}
+
+	if targetPhase != "delete" && targetPhase != "frozen" {
+		check, err := moveCapacityCheck(ctx, client, candidates, policies, targetPhase, maxFillPct)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.Writer, "\nCapacity check:\n%s\n\n", check)
+
+		if !check.Fits() {
+			switch {
+			case force:
+				fmt.Fprintf(cmd.Writer, "WARNING: data to move does not fit into tier %q, moving anyway since --force is given\n", targetPhase)
+			case dryRun:
+				fmt.Fprintf(cmd.Writer, "%sdata to move does not fit into tier %q, the move would be refused without --force\n", dryRunPrefix, targetPhase)
+			default:
+				return fmt.Errorf("data to move does not fit into tier %q, use --force to move anyway", targetPhase)
+			}
+		}
+	}
+
+	if !dryRun {
+		if journalFilename == "" {
+			journalFilename = defaultJournalFilename(cmd.String("deployment"), time.Now())
+		}
+
+		err = writeMoveJournal(journalFilename, cmd.String("deployment"), cmd.String("region"), candidates, target)
+		if err != nil {
+			return fmt.Errorf("failed to write journal: %w", err)
+		}
+
+		fmt.Fprintf(cmd.Writer, "journal with the original ILM state of the indices written to %s, use \"ec_check ilm undo %s\" to move them back\n", journalFilename, journalFilename)
+	}
+
+	batches := [][]indexDetails{candidates}
+	if batchSize > 0 {
+		batches = slices.Collect(slices.Chunk(candidates, batchSize))
+	}
+
+	for i, batch := range batches {
+		if len(batches) > 1 {
+			fmt.Fprintf(cmd.Writer, "%sbatch %d/%d: moving %d indices\n", dryRunPrefix, i+1, len(batches), len(batch))
+		}
+
+		results = append(results, moveBatch(ctx, cmd, client, batch, target, maxInFlight, dryRun)...)
+
+		if dryRun || i == len(batches)-1 {
 			continue
 		}
 
-		if !force && (*managed.Action != "complete" || *managed.Step != "complete") {
-			fmt.Printf(`index %q is not in "complete" state (action: %q, step: %q) in its phase and --force is not given, skipping`+"\n", managed.Index, *managed.Action, *managed.Step)
+		if pause > 0 {
+			fmt.Fprintf(cmd.Writer, "pausing for %s\n", pause)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(pause):
+			}
+		}
+
+		err = waitForClusterSettle(ctx, cmd, client, settleTimeout)
+		if err != nil {
+			for _, index := range slices.Concat(batches[i+1:]...) {
+				results = append(results, moveResult{index: index.name, result: moveSkipped, details: "cluster did not settle"})
+			}
+
+			return errors.Join(err, moveSummary(cmd, results))
+		}
+	}
+
+	summaryErr := moveSummary(cmd, results)
+	if !wait || dryRun {
+		return summaryErr
+	}
+
+	moved := make([]string, 0, len(results))
+	for _, result := range results {
+		if result.result == moveSucceeded {
+			moved = append(moved, result.index)
+		}
+	}
+
+	// The successfully moved indices are waited for, even if the move of
+	// other indices failed.
+	return errors.Join(summaryErr, waitForMoveCompletion(ctx, cmd, client, moved, targetPhase, waitTimeout, format))
+}
+
+// waitForMoveCompletion polls the ILM state of the moved indices until each
+// of them has completed the target phase or failed, or the timeout is reached.
+// The progress is shown as table, which is refreshed on every poll. If at
+// least one index failed or did not complete within the timeout, an error is
+// returned.
+func waitForMoveCompletion(ctx context.Context, cmd *cli.Command, client *elasticsearch.TypedClient, indices []string, targetPhase string, timeout time.Duration, format string) error {
+	if len(indices) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(cmd.Writer, "\nwaiting up to %s for %d indices to complete phase %q\n", timeout, len(indices), targetPhase)
+
+	sort.Strings(indices)
+
+	live := newLiveRenderer(cmd.Writer)
+	deadline := time.Now().Add(timeout)
+	for {
+		// Explaining a deleted index by its name fails and the searchable
+		// snapshot action renames the indices, therefore all indices are
+		// explained.
+		ilms, err := client.Ilm.ExplainLifecycle("_all").OnlyManaged(true).Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		counts := make(map[string]int, 4)
+		data := make([][]string, 0, len(indices))
+		for _, name := range indices {
+			managed := explainMovedIndex(ilms.Indices, name)
+			state := moveWaitState(managed, targetPhase)
+			counts[state]++
+
+			index := name
+			var phase, action, step, reason string
+			if managed != nil {
+				if managed.Index != name {
+					index = fmt.Sprintf("%s (%s)", name, managed.Index)
+				}
+
+				phase, action, step = deref(managed.Phase), deref(managed.Action), deref(managed.Step)
+				if state == waitStateFailed {
+					_, reason = classifyStepInfo(managed.StepInfo)
+				}
+			}
+
+			data = append(data, []string{index, phase, action, step, state, reason})
+		}
+
+		buf := &strings.Builder{}
+		fmt.Fprintf(buf, "%s: %d complete, %d deleted, %d failed, %d pending\n", time.Now().Format(time.TimeOnly), counts[waitStateComplete], counts[waitStateDeleted], counts[waitStateFailed], counts[waitStatePending])
+
+		table := newTable(buf, format)
+		table.Header([]string{
+			"Index", "Phase", "Action", "Step", "State", "Reason",
+		})
+		err = table.Bulk(data)
+		if err != nil {
+			return err
+		}
+
+		err = table.Render()
+		if err != nil {
+			return err
+		}
+
+		live.Render(buf.String())
+
+		if counts[waitStatePending] == 0 {
+			if counts[waitStateFailed] > 0 {
+				return fmt.Errorf("%d of %d moved indices failed in phase %q", counts[waitStateFailed], len(indices), targetPhase)
+			}
+
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d of %d moved indices did not complete phase %q within %s (%d failed)", counts[waitStatePending], len(indices), targetPhase, timeout, counts[waitStateFailed])
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+// searchableSnapshotPrefixes are the prefixes of the names of the indices
+// mounted by the searchable snapshot action, which replace the original index.
+var searchableSnapshotPrefixes = []string{"restored-", "partial-", "partial-restored-"}
+
+// explainMovedIndex returns the ILM explain information of a moved index. If
+// the index has been replaced by a mounted searchable snapshot, the
+// information of the mounted index is returned. nil is returned, if neither
+// the index nor a mounted index is managed by ILM.
+func explainMovedIndex(indices map[string]types.LifecycleExplain, name string) *types.LifecycleExplainManaged {
+	if managed, ok := indices[name].(*types.LifecycleExplainManaged); ok {
+		return managed
+	}
+
+	for _, prefix := range searchableSnapshotPrefixes {
+		if managed, ok := indices[prefix+name].(*types.LifecycleExplainManaged); ok {
+			return managed
+		}
+	}
+
+	return nil
+}
+
+// moveWaitState returns the state of a moved index based on its ILM explain
+// information. managed is nil, if the index is not managed by ILM (anymore),
+// which is considered as deleted for the delete phase and failed otherwise.
+// An index, which already entered a phase after the target phase, has
+// completed the target phase.
+func moveWaitState(managed *types.LifecycleExplainManaged, targetPhase string) string {
+	switch {
+	case managed == nil && targetPhase == "delete":
+		return waitStateDeleted
+	case managed == nil:
+		return waitStateFailed
+	case deref(managed.Step) == "ERROR":
+		return waitStateFailed
+	case deref(managed.Phase) == targetPhase && deref(managed.Action) == "complete" && deref(managed.Step) == "complete":
+		return waitStateComplete
+	case phaseAfter(deref(managed.Phase), targetPhase):
+		return waitStateComplete
+	default:
+		return waitStatePending
+	}
+}
+
+// phaseAfter returns if phase comes after the target phase in the order of
+// the ILM phases. Unknown phases (e.g. "new") never come after the target
+// phase.
+func phaseAfter(phase string, targetPhase string) bool {
+	order, ok := phaseOrder[phase]
+	if !ok {
+		return false
+	}
+
+	targetOrder, ok := phaseOrder[targetPhase]
+
+	return ok && order > targetOrder
+}
+
+// moveCapacityCheck checks, if the indices to move fit into the data tier of
+// the target phase. For target phases with searchable snapshot action, only
+// the primary shards are considered, since searchable snapshots are mounted
+// without replicas.
+func moveCapacityCheck(ctx context.Context, client *elasticsearch.TypedClient, indices []indexDetails, policies getlifecycle.Response, targetPhase string, maxFillPct float64) (CapacityCheck, error) {
+	var required float64
+	for _, index := range indices {
+		if index.phase == targetPhase {
+			// Moving within the phase does not move data between tiers.
 			continue
 		}
 
-		policy, ok := policies[*managed.Policy]
+		required += float64(phaseSize(policies[index.policy].Policy, targetPhase, index))
+	}
+
+	allocations, err := getAllocations(ctx, client)
+	if err != nil {
+		return CapacityCheck{}, err
+	}
+
+	watermark, err := getLowDiskWatermark(ctx, client)
+	if err != nil {
+		return CapacityCheck{}, err
+	}
+
+	usage, err := tierDiskUsage(allocations)
+	if err != nil {
+		return CapacityCheck{}, err
+	}
+
+	tier := Tier(targetPhase)
+
+	return calcCapacityCheck(tier, usage[tier], required, maxFillPct, watermark), nil
+}
+
+// getAllocations returns the disk allocation information of all nodes in the
+// cluster.
+func getAllocations(ctx context.Context, client *elasticsearch.TypedClient) ([]Allocation, error) {
+	records, err := client.Cat.Allocation().Bytes(bytes.B).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := make([]Allocation, 0, len(records))
+	for _, record := range records {
+		allocation := Allocation{
+			NodeRole: deref(record.NodeRole),
+		}
+
+		if record.DiskUsed != nil {
+			allocation.DiskUsed = fmt.Sprint(record.DiskUsed)
+		}
+
+		if record.DiskTotal != nil {
+			allocation.DiskTotal = fmt.Sprint(record.DiskTotal)
+		}
+
+		allocations = append(allocations, allocation)
+	}
+
+	return allocations, nil
+}
+
+// getLowDiskWatermark returns the effective low disk watermark of the cluster.
+func getLowDiskWatermark(ctx context.Context, client *elasticsearch.TypedClient) (diskWatermark, error) {
+	const lowWatermarkSetting = "cluster.routing.allocation.disk.watermark.low"
+
+	settings, err := client.Cluster.GetSettings().IncludeDefaults(true).FlatSettings(true).Do(ctx)
+	if err != nil {
+		return diskWatermark{}, err
+	}
+
+	for _, scope := range []map[string]json.RawMessage{settings.Transient, settings.Persistent, settings.Defaults} {
+		value, ok := scope[lowWatermarkSetting]
 		if !ok {
-			return fmt.Errorf("policy %q not found", *managed.Policy)
-		}
-
-		var policyPhaseDefinition *types.Phase
-		switch targetPhase {
-		case "hot":
-			policyPhaseDefinition = policy.Policy.Phases.Hot
-		case "warm":
-			policyPhaseDefinition = policy.Policy.Phases.Warm
-		case "cold":
-			policyPhaseDefinition = policy.Policy.Phases.Cold
-		case "frozen":
-			policyPhaseDefinition = policy.Policy.Phases.Frozen
-		case "delete":
-			policyPhaseDefinition = policy.Policy.Phases.Delete
-		}
-		if policyPhaseDefinition == nil {
-			fmt.Printf("target phase %q is not defined in policy %q used by index %q\n", targetPhase, *managed.Phase, managed.Index)
 			continue
 		}
 
-		fmt.Printf("%s move %q (phase: %q, action: %q, step: %q, policy: %q) to phase %q\n", dryRunPrefix, managed.Index, *managed.Phase, *managed.Action, *managed.Step, *managed.Policy, targetPhase)
-		if dryRun {
-			continue
+		var watermark string
+		err = json.Unmarshal(value, &watermark)
+		if err != nil {
+			return diskWatermark{}, fmt.Errorf("failed to read setting %q: %w", lowWatermarkSetting, err)
 		}
 
-		resp, err := client.Ilm.MoveToStep(managed.Index).CurrentStep(&types.StepKey{
-			Phase:  *managed.Phase,
-			Action: managed.Action,
-			Name:   managed.Step,
-		}).NextStep(&types.StepKey{
-			Phase: targetPhase,
-		}).Do(ctx)
+		return parseDiskWatermark(watermark)
+	}
+
+	return diskWatermark{pct: 85}, nil
+}
+
+// moveBatch moves the given indices to the target with at most
+// maxInFlight move operations executed concurrently. Failing move operations
+// do not stop the processing of the remaining indices.
+func moveBatch(ctx context.Context, cmd *cli.Command, client *elasticsearch.TypedClient, batch []indexDetails, target moveTarget, maxInFlight int, dryRun bool) []moveResult {
+	var dryRunPrefix string
+	if dryRun {
+		dryRunPrefix = "(DRY RUN) "
+	}
+
+	results := make([]moveResult, len(batch))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxInFlight)
+
+	for i, index := range batch {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			mu.Lock()
+			fmt.Fprintf(cmd.Writer, "%smove %q (phase: %q, action: %q, step: %q, policy: %q) to %s\n", dryRunPrefix, index.name, index.phase, index.action, index.step, index.policy, target)
+			mu.Unlock()
+
+			results[i] = moveResult{index: index.name, result: moveSucceeded}
+			if dryRun {
+				return
+			}
+
+			err := moveToStep(ctx, client, index, target)
+			if err != nil {
+				mu.Lock()
+				fmt.Fprintf(cmd.Writer, "move of %q failed: %v\n", index.name, err)
+				mu.Unlock()
+
+				results[i] = moveResult{index: index.name, result: moveFailed, details: err.Error()}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// moveToStep moves an ILM managed index from its current step to the target.
+func moveToStep(ctx context.Context, client *elasticsearch.TypedClient, index indexDetails, target moveTarget) error {
+	resp, err := client.Ilm.MoveToStep(index.name).CurrentStep(&types.StepKey{
+		Phase:  index.phase,
+		Action: &index.action,
+		Name:   &index.step,
+	}).NextStep(target.stepKey()).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !resp.Acknowledged {
+		return fmt.Errorf("move operation for %q has not been acknowledged", index.name)
+	}
+
+	return nil
+}
+
+// waitForClusterSettle waits until the cluster has no relocating and no
+// initializing shards and the cluster health is not red, or the timeout is
+// reached.
+func waitForClusterSettle(ctx context.Context, cmd *cli.Command, client *elasticsearch.TypedClient, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		health, err := client.Cluster.Health().Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		if health.RelocatingShards == 0 && health.InitializingShards == 0 && health.Status != healthstatus.Red {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("cluster did not settle within %s (status: %s, relocating shards: %d, initializing shards: %d)", timeout, health.Status, health.RelocatingShards, health.InitializingShards)
+		}
+
+		fmt.Fprintf(cmd.Writer, "waiting for cluster to settle (status: %s, relocating shards: %d, initializing shards: %d)\n", health.Status, health.RelocatingShards, health.InitializingShards)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(settlePollInterval):
+		}
+	}
+}
+
+// checkMoveCandidate checks, if the index can be moved to the target. If not,
+// the returned moveResult contains the result and the details for the move
+// summary and reason the explanation for the user. An index, which is not in
+// the "complete" state of its phase, is only moved with force.
+func checkMoveCandidate(index indexDetails, target moveTarget, policies getlifecycle.Response, force bool) (*moveResult, string, error) {
+	if index.writeIndex {
+		return &moveResult{index: index.name, result: moveSkipped, details: "write index of data stream"},
+			fmt.Sprintf("index %q is the write index of data stream %q", index.name, index.dataStream), nil
+	}
+
+	if index.phase == target.phase && target.action == "" {
+		return &moveResult{index: index.name, result: moveSkipped, details: "already in target phase"},
+			fmt.Sprintf("index %q is already in phase %q", index.name, index.phase), nil
+	}
+
+	if !force && !isPhaseComplete(index) {
+		return &moveResult{index: index.name, result: moveSkipped, details: "not in complete state"},
+			fmt.Sprintf(`index %q is not in "complete" state (action: %q, step: %q) in its phase and --force is not given`, index.name, index.action, index.step), nil
+	}
+
+	policy, ok := policies[index.policy]
+	if !ok {
+		return &moveResult{index: index.name, result: moveFailed, details: fmt.Sprintf("policy %q not found", index.policy)},
+			fmt.Sprintf("policy %q used by index %q not found", index.policy, index.name), nil
+	}
+
+	policyPhaseDefinition := policyPhase(policy.Policy, target.phase)
+	if policyPhaseDefinition == nil {
+		return &moveResult{index: index.name, result: moveSkipped, details: "target phase not defined in policy"},
+			fmt.Sprintf("target phase %q is not defined in policy %q used by index %q", target.phase, index.policy, index.name), nil
+	}
+
+	if target.action != "" {
+		actions, err := phaseActions(policyPhaseDefinition)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if !slices.Contains(actions, target.action) {
+			return &moveResult{index: index.name, result: moveSkipped, details: "target action not defined in policy phase"},
+				fmt.Sprintf("target action %q is not defined in phase %q of policy %q used by index %q, defined actions: %v", target.action, target.phase, index.policy, index.name, actions), nil
+		}
+	}
+
+	return nil, "", nil
+}
+
+// isPhaseComplete returns true, if the index completed all actions of its
+// phase.
+func isPhaseComplete(index indexDetails) bool {
+	return index.action == "complete" && index.step == "complete"
+}
+
+// moveSummary prints the number of succeeded, skipped and failed indices as
+// well as the details of the not succeeded indices. If at least one index
+// failed, an error is returned.
+func moveSummary(cmd *cli.Command, results []moveResult) error {
+	counts := make(map[string]int, 3)
+	data := make([][]string, 0, len(results))
+	for _, result := range results {
+		counts[result.result]++
+		if result.result != moveSucceeded {
+			data = append(data, []string{result.index, result.result, result.details})
+		}
+	}
+
+	sort.SliceStable(data, func(i, j int) bool {
+		return data[i][0] < data[j][0]
+	})
+
+	fmt.Fprintf(cmd.Writer, "\nSummary: %d succeeded, %d skipped, %d failed\n", counts[moveSucceeded], counts[moveSkipped], counts[moveFailed])
+
+	if len(data) > 0 {
+		table := newTable(cmd.Writer, "")
+		table.Header([]string{
+			"Index", "Result", "Details",
+		})
+		err := table.Bulk(data)
 		if err != nil {
 			return err
 		}
 
-		if !resp.Acknowledged {
-			return fmt.Errorf("move operation for %q has not been acknowledged", managed.Index)
+		err = table.Render()
+		if err != nil {
+			return err
 		}
 	}
 
+	if counts[moveFailed] > 0 {
+		return fmt.Errorf("move failed for %d of %d indices", counts[moveFailed], len(results))
+	}
+
 	return nil
 }
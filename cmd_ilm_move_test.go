@@ -0,0 +1,210 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_moveWaitState(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name        string
+		managed     *types.LifecycleExplainManaged
+		targetPhase string
+
+		wantState string
+	}{
+		{
+			name:        "complete",
+			managed:     &types.LifecycleExplainManaged{Phase: ptr("cold"), Action: ptr("complete"), Step: ptr("complete")},
+			targetPhase: "cold",
+
+			wantState: waitStateComplete,
+		},
+		{
+			name:        "running",
+			managed:     &types.LifecycleExplainManaged{Phase: ptr("cold"), Action: ptr("searchable_snapshot"), Step: ptr("wait-for-snapshot")},
+			targetPhase: "cold",
+
+			wantState: waitStatePending,
+		},
+		{
+			name:        "complete in previous phase",
+			managed:     &types.LifecycleExplainManaged{Phase: ptr("warm"), Action: ptr("complete"), Step: ptr("complete")},
+			targetPhase: "cold",
+
+			wantState: waitStatePending,
+		},
+		{
+			name:        "completed and moved to next phase",
+			managed:     &types.LifecycleExplainManaged{Phase: ptr("frozen"), Action: ptr("searchable_snapshot"), Step: ptr("wait-for-snapshot")},
+			targetPhase: "cold",
+
+			wantState: waitStateComplete,
+		},
+		{
+			name:        "new index",
+			managed:     &types.LifecycleExplainManaged{Phase: ptr("new"), Action: ptr("complete"), Step: ptr("complete")},
+			targetPhase: "cold",
+
+			wantState: waitStatePending,
+		},
+		{
+			name:        "error",
+			managed:     &types.LifecycleExplainManaged{Phase: ptr("cold"), Action: ptr("searchable_snapshot"), Step: ptr("ERROR")},
+			targetPhase: "cold",
+
+			wantState: waitStateFailed,
+		},
+		{
+			name:        "deleted",
+			managed:     nil,
+			targetPhase: "delete",
+
+			wantState: waitStateDeleted,
+		},
+		{
+			name:        "no longer managed",
+			managed:     nil,
+			targetPhase: "cold",
+
+			wantState: waitStateFailed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.wantState, moveWaitState(tc.managed, tc.targetPhase))
+		})
+	}
+}
+
+func Test_moveTarget_stepKey(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name   string
+		target moveTarget
+
+		wantStepKey *types.StepKey
+	}{
+		{
+			name:   "phase",
+			target: moveTarget{phase: "cold"},
+
+			wantStepKey: &types.StepKey{Phase: "cold"},
+		},
+		{
+			name:   "action",
+			target: moveTarget{phase: "warm", action: "forcemerge"},
+
+			wantStepKey: &types.StepKey{Phase: "warm", Action: ptr("forcemerge")},
+		},
+		{
+			name:   "step",
+			target: moveTarget{phase: "cold", action: "searchable_snapshot", step: "create-snapshot"},
+
+			wantStepKey: &types.StepKey{Phase: "cold", Action: ptr("searchable_snapshot"), Name: ptr("create-snapshot")},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.wantStepKey, tc.target.stepKey())
+		})
+	}
+}
+
+func Test_phaseActions(t *testing.T) {
+	actions, err := phaseActions(&types.Phase{
+		Actions: &types.IlmActions{
+			Forcemerge: &types.ForceMergeAction{MaxNumSegments: 1},
+			Allocate:   &types.AllocateAction{},
+			Readonly:   &types.EmptyObject{},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"allocate", "forcemerge", "readonly"}, actions)
+
+	actions, err = phaseActions(nil)
+	require.NoError(t, err)
+	require.Empty(t, actions)
+}
+
+func Test_explainMovedIndex(t *testing.T) {
+	indices := map[string]types.LifecycleExplain{
+		"logs-1":          &types.LifecycleExplainManaged{Index: "logs-1"},
+		"restored-logs-2": &types.LifecycleExplainManaged{Index: "restored-logs-2"},
+		"partial-logs-3":  &types.LifecycleExplainManaged{Index: "partial-logs-3"},
+		"logs-4":          &types.LifecycleExplainUnmanaged{Index: "logs-4"},
+	}
+
+	tests := []struct {
+		name string
+
+		wantIndex string
+	}{
+		{name: "logs-1", wantIndex: "logs-1"},
+		{name: "logs-2", wantIndex: "restored-logs-2"},
+		{name: "logs-3", wantIndex: "partial-logs-3"},
+		{name: "logs-4"},
+		{name: "logs-5"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			managed := explainMovedIndex(indices, tc.name)
+			if tc.wantIndex == "" {
+				require.Nil(t, managed)
+				return
+			}
+
+			require.Equal(t, tc.wantIndex, managed.Index)
+		})
+	}
+}
+
+func Test_validateTargetStep(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+		step   string
+
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			action: "forcemerge",
+			step:   "segment-count",
+		},
+		{
+			name:   "step of other action",
+			action: "forcemerge",
+			step:   "check-allocation",
+
+			wantErr: `target-step "check-allocation" is invalid for action "forcemerge", valid values are: [check-not-write-index readonly forcemerge segment-count]`,
+		},
+		{
+			name:   "action without supported steps",
+			action: "shrink",
+			step:   "shrink",
+
+			wantErr: `target-step is not supported for action "shrink"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateTargetStep(tc.action, tc.step)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/urfave/cli/v3"
+)
+
+func ilmPolicies(ctx context.Context, cmd *cli.Command) error {
+	ilmPolicy := cmd.String("ilm-policy")
+	format := cmd.String("format")
+
+	client, err := newTypedClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	policies, err := client.Ilm.GetLifecycle().Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, totalSizes, err := getIndexSizes(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	ilms, err := client.Ilm.ExplainLifecycle("_all").OnlyManaged(true).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	indexCounts := make(map[string]int, len(policies))
+	indexSizes := make(map[string]int64, len(policies))
+	for index, ilm := range ilms.Indices {
+		managed, ok := ilm.(*types.LifecycleExplainManaged)
+		if !ok {
+			continue
+		}
+
+		indexCounts[*managed.Policy]++
+		indexSizes[*managed.Policy] += totalSizes[index]
+	}
+
+	data := [][]string{}
+	for name, policy := range mapOrderedByKey(policies) {
+		if ilmPolicy != "" && ilmPolicy != name {
+			continue
+		}
+
+		first := true
+		for _, phaseName := range ilmPhases {
+			phase := policyPhase(policy.Policy, phaseName)
+			if phase == nil {
+				continue
+			}
+
+			minAge, err := phaseMinAge(phase)
+			if err != nil {
+				return fmt.Errorf("failed to parse min_age of phase %q of policy %q: %w", phaseName, name, err)
+			}
+
+			var rollover string
+			var actions string
+			if phase.Actions != nil {
+				if phase.Actions.Rollover != nil {
+					rollover, err = formatActionSettings(phase.Actions.Rollover)
+					if err != nil {
+						return err
+					}
+				}
+
+				actions, err = formatActions(phase.Actions)
+				if err != nil {
+					return err
+				}
+			}
+
+			policyName, indices, totalSize := "", "", ""
+			if first {
+				policyName = name
+				indices = strconv.Itoa(indexCounts[name])
+				totalSize = units.BytesSize(float64(indexSizes[name]))
+				first = false
+			}
+
+			data = append(data, []string{policyName, indices, totalSize, phaseName, formatDuration(minAge), rollover, actions})
+		}
+	}
+
+	table := newTable(cmd.Writer, format)
+	table.Header([]string{
+		"Policy", "Indices", "Total Size", "Phase", "Min Age", "Rollover", "Actions",
+	})
+	err = table.Bulk(data)
+	if err != nil {
+		return err
+	}
+
+	return table.Render()
+}
+
+func ilmPoliciesDiff(ctx context.Context, cmd *cli.Command) error {
+	otherDeployment := cmd.String("other-deployment")
+	otherRegion := cmd.String("other-region")
+	otherUsername := cmd.String("other-username")
+	otherPassword := cmd.String("other-password")
+	exitCode := cmd.Bool("exit-code")
+	format := cmd.String("format")
+
+	if cmd.Args().Len() < 1 || cmd.Args().Len() > 2 {
+		return fmt.Errorf("expected the names of the policies to compare: <policy-a> [<policy-b>]")
+	}
+
+	policyNameA := cmd.Args().Get(0)
+	policyNameB := cmd.Args().Get(1)
+	if policyNameB == "" {
+		policyNameB = policyNameA
+	}
+
+	if otherDeployment == "" && policyNameA == policyNameB {
+		return fmt.Errorf("comparing policy %q with itself, provide a second policy or --other-deployment", policyNameA)
+	}
+
+	clientA, err := newTypedClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	clientB := clientA
+	if otherDeployment != "" {
+		if otherRegion == "" {
+			otherRegion = cmd.String("region")
+		}
+
+		clientB, err = newDeploymentClient(otherDeployment, otherRegion, otherUsername, otherPassword)
+		if err != nil {
+			return err
+		}
+	}
+
+	policiesA, err := clientA.Ilm.GetLifecycle().Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	policyA, ok := policiesA[policyNameA]
+	if !ok {
+		return fmt.Errorf("policy %q not found", policyNameA)
+	}
+
+	policiesB, err := clientB.Ilm.GetLifecycle().Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	policyB, ok := policiesB[policyNameB]
+	if !ok {
+		return fmt.Errorf("policy %q not found", policyNameB)
+	}
+
+	labelA, labelB := policyNameA, policyNameB
+	if otherDeployment != "" {
+		labelA = cmd.String("deployment") + "/" + policyNameA
+		labelB = otherDeployment + "/" + policyNameB
+	}
+
+	differences, err := diffPolicies(policyA.Policy, policyB.Policy)
+	if err != nil {
+		return err
+	}
+
+	if len(differences) == 0 {
+		fmt.Fprintf(cmd.Writer, "Policies %q and %q are identical\n", labelA, labelB)
+		return nil
+	}
+
+	data := make([][]string, 0, len(differences))
+	for _, difference := range differences {
+		data = append(data, []string{difference.setting, difference.a, difference.b})
+	}
+
+	table := newTable(cmd.Writer, format)
+	table.Header([]string{
+		"Setting", labelA, labelB,
+	})
+	err = table.Bulk(data)
+	if err != nil {
+		return err
+	}
+
+	err = table.Render()
+	if err != nil {
+		return err
+	}
+
+	if exitCode {
+		return cli.Exit("Policies differ", 2)
+	}
+
+	return nil
+}
+
+// formatActions returns the names of the given actions each with its settings
+// on a separate line.
+func formatActions(actions *types.IlmActions) (string, error) {
+	actionSettings, err := toJSONMap(actions)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, 0, len(actionSettings))
+	for name, settings := range mapOrderedByKey(actionSettings) {
+		settingsStr, err := formatActionSettings(settings)
+		if err != nil {
+			return "", err
+		}
+
+		if settingsStr == "" {
+			lines = append(lines, name)
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("%s (%s)", name, settingsStr))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// formatActionSettings returns the settings of an action as comma separated
+// key=value pairs ordered by key.
+func formatActionSettings(action any) (string, error) {
+	flat, err := flattenJSON(action)
+	if err != nil {
+		return "", err
+	}
+
+	settings := make([]string, 0, len(flat))
+	for key, value := range mapOrderedByKey(flat) {
+		settings = append(settings, key+"="+value)
+	}
+
+	return strings.Join(settings, ", "), nil
+}
+
+// policyDifference contains a setting, which differs between two policies,
+// with its values. If a setting is not present in a policy, the value is "-".
+type policyDifference struct {
+	setting string
+	a       string
+	b       string
+}
+
+// diffPolicies returns the settings, which differ between the policies a and b,
+// ordered by the phases and the setting names. Settings, which are not given
+// and have the default value in the other policy, do not differ.
+func diffPolicies(a, b types.IlmPolicy) ([]policyDifference, error) {
+	mapA, err := policyJSONMap(a)
+	if err != nil {
+		return nil, err
+	}
+
+	mapB, err := policyJSONMap(b)
+	if err != nil {
+		return nil, err
+	}
+
+	flatA, err := flattenJSON(mapA)
+	if err != nil {
+		return nil, err
+	}
+
+	flatB, err := flattenJSON(mapB)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]struct{}, len(flatA)+len(flatB))
+	for setting := range flatA {
+		settings[setting] = struct{}{}
+	}
+
+	for setting := range flatB {
+		settings[setting] = struct{}{}
+	}
+
+	differences := []policyDifference{}
+	for setting := range settings {
+		valueA, okA := flatA[setting]
+		valueB, okB := flatB[setting]
+		if okA && okB && valueA == valueB {
+			continue
+		}
+
+		if !okA {
+			valueA = "-"
+		}
+
+		if !okB {
+			valueB = "-"
+		}
+
+		differences = append(differences, policyDifference{setting: setting, a: valueA, b: valueB})
+	}
+
+	sort.Slice(differences, func(i, j int) bool {
+		phaseI, phaseJ := settingPhase(differences[i].setting), settingPhase(differences[j].setting)
+		if phaseI != phaseJ {
+			return phaseLess(phaseI, phaseJ)
+		}
+
+		return differences[i].setting < differences[j].setting
+	})
+
+	return differences, nil
+}
+
+// settingPhase returns the phase of a flattened policy setting like
+// phases.warm.min_age.
+func settingPhase(setting string) string {
+	parts := strings.SplitN(setting, ".", 3)
+	if len(parts) < 2 || parts[0] != "phases" {
+		return ""
+	}
+
+	return parts[1]
+}
+
+// toJSONMap converts v to a map by its JSON representation.
+func toJSONMap(v any) (map[string]any, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	m := map[string]any{}
+	err = json.Unmarshal(body, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// flattenJSON converts v to its JSON representation and returns all leaf values
+// keyed by their path with the keys joined by ".". Arrays are treated as leaf
+// values.
+func flattenJSON(v any) (map[string]string, error) {
+	m, err := toJSONMap(v)
+	if err != nil {
+		return nil, err
+	}
+
+	flat := map[string]string{}
+	err = flatten(flat, "", m)
+	if err != nil {
+		return nil, err
+	}
+
+	return flat, nil
+}
+
+func flatten(flat map[string]string, prefix string, v any) error {
+	switch value := v.(type) {
+	case map[string]any:
+		if len(value) == 0 && prefix != "" {
+			// Keep empty objects like actions without settings (e.g. readonly).
+			flat[prefix] = "{}"
+			return nil
+		}
+
+		for key, child := range value {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+
+			err := flatten(flat, path, child)
+			if err != nil {
+				return err
+			}
+		}
+
+	case string:
+		flat[prefix] = value
+
+	default:
+		body, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		flat[prefix] = string(body)
+	}
+
+	return nil
+}
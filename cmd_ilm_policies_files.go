@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/ilm/putlifecycle"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+var policyFileExtensions = map[string]string{
+	".json": "json",
+	".yaml": "yaml",
+	".yml":  "yaml",
+}
+
+func ilmPoliciesExport(ctx context.Context, cmd *cli.Command) error {
+	dir := cmd.String("dir")
+	format := cmd.String("format")
+	ilmPolicy := cmd.String("ilm-policy")
+	skipManaged := cmd.Bool("skip-managed")
+
+	allowedFormats := []string{"json", "yaml"}
+	if !slices.Contains(allowedFormats, format) {
+		return fmt.Errorf("format %q is invalid, valid values are: %v", format, allowedFormats)
+	}
+
+	client, err := newTypedClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	policies, err := client.Ilm.GetLifecycle().Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	for name, policy := range mapOrderedByKey(policies) {
+		if ilmPolicy != "" && ilmPolicy != name {
+			continue
+		}
+
+		if skipManaged && isManagedPolicy(policy.Policy) {
+			verbosef(cmd, "skipping managed policy %q\n", name)
+			continue
+		}
+
+		body, err := marshalPolicy(policy.Policy, format)
+		if err != nil {
+			return fmt.Errorf("failed to marshal policy %q: %w", name, err)
+		}
+
+		filename := filepath.Join(dir, name+"."+format)
+		err = os.WriteFile(filename, body, 0o644)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.Writer, "exported policy %q to %s\n", name, filename)
+	}
+
+	return nil
+}
+
+func ilmPoliciesApply(ctx context.Context, cmd *cli.Command) error {
+	dir := cmd.String("dir")
+	dryRun := cmd.Bool("dry-run")
+
+	var dryRunPrefix string
+	if dryRun {
+		dryRunPrefix = "(DRY RUN) "
+	}
+
+	filePolicies, err := readPolicyFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	client, err := newTypedClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	policies, err := client.Ilm.GetLifecycle().Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	for name, filePolicy := range mapOrderedByKey(filePolicies) {
+		desired, err := marshalPolicy(filePolicy, "json")
+		if err != nil {
+			return fmt.Errorf("failed to marshal policy %q: %w", name, err)
+		}
+
+		operation := "create"
+		var current []byte
+		if policy, ok := policies[name]; ok {
+			operation = "update"
+			current, err = marshalPolicy(policy.Policy, "json")
+			if err != nil {
+				return fmt.Errorf("failed to marshal policy %q: %w", name, err)
+			}
+		}
+
+		if string(current) == string(desired) {
+			fmt.Fprintf(cmd.Writer, "policy %q is unchanged, skipping\n", name)
+			continue
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(current)),
+			B:        difflib.SplitLines(string(desired)),
+			FromFile: "cluster/" + name,
+			ToFile:   "file/" + name,
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.Writer, "%s%s policy %q\n%s\n", dryRunPrefix, operation, name, diff)
+		if dryRun {
+			continue
+		}
+
+		resp, err := client.Ilm.PutLifecycle(name).Request(&putlifecycle.Request{
+			Policy: &filePolicy,
+		}).Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Acknowledged {
+			return fmt.Errorf("%s operation for policy %q has not been acknowledged", operation, name)
+		}
+	}
+
+	return nil
+}
+
+// readPolicyFiles reads all ILM policies from the JSON and YAML files in dir.
+// The name of the policy is the file name without extension.
+func readPolicyFiles(dir string) (map[string]types.IlmPolicy, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make(map[string]types.IlmPolicy, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		ext := filepath.Ext(entry.Name())
+		format, ok := policyFileExtensions[ext]
+		if !ok {
+			continue
+		}
+
+		name := strings.TrimSuffix(entry.Name(), ext)
+		if _, ok := policies[name]; ok {
+			return nil, fmt.Errorf("policy %q is defined in multiple files", name)
+		}
+
+		body, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		policy, err := unmarshalPolicy(body, format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy file %q: %w", entry.Name(), err)
+		}
+
+		policies[name] = policy
+	}
+
+	return policies, nil
+}
+
+// marshalPolicy returns the normalized representation of an ILM policy in the
+// given format (json or yaml). The keys are ordered alphabetically and the
+// default values added by Elasticsearch are set, which allows to compare and
+// diff policies from files with the policies of the cluster textually.
+func marshalPolicy(policy types.IlmPolicy, format string) ([]byte, error) {
+	m, err := policyJSONMap(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "yaml" {
+		return yaml.Marshal(m)
+	}
+
+	body, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(body, '\n'), nil
+}
+
+// policyActionDefaults are the default values of the action options, which
+// Elasticsearch adds to the stored policies.
+var policyActionDefaults = map[string]map[string]any{
+	"delete":              {"delete_searchable_snapshot": true},
+	"migrate":             {"enabled": true},
+	"searchable_snapshot": {"force_merge_index": true},
+	"shrink":              {"allow_write_after_shrink": false},
+}
+
+// policyJSONMap converts the policy to a map by its JSON representation with
+// the default values added by Elasticsearch set.
+func policyJSONMap(policy types.IlmPolicy) (map[string]any, error) {
+	m, err := toJSONMap(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	addPolicyDefaults(m)
+
+	return m, nil
+}
+
+// addPolicyDefaults sets the default values, which Elasticsearch adds to the
+// stored policies, in the JSON map of a policy, if they are not given: the
+// min_age of the phases and the options of policyActionDefaults.
+func addPolicyDefaults(policy map[string]any) {
+	phases, _ := policy["phases"].(map[string]any)
+	for _, p := range phases {
+		phase, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if _, ok := phase["min_age"]; !ok {
+			phase["min_age"] = "0ms"
+		}
+
+		actions, _ := phase["actions"].(map[string]any)
+		for name, defaults := range policyActionDefaults {
+			action, ok := actions[name].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			for key, value := range defaults {
+				if _, ok := action[key]; !ok {
+					action[key] = value
+				}
+			}
+		}
+	}
+}
+
+// unmarshalPolicy parses an ILM policy in the given format (json or yaml).
+func unmarshalPolicy(body []byte, format string) (types.IlmPolicy, error) {
+	var policy types.IlmPolicy
+
+	if format == "yaml" {
+		m := map[string]any{}
+		err := yaml.Unmarshal(body, &m)
+		if err != nil {
+			return policy, err
+		}
+
+		body, err = json.Marshal(m)
+		if err != nil {
+			return policy, err
+		}
+	}
+
+	err := json.Unmarshal(body, &policy)
+	return policy, err
+}
+
+// isManagedPolicy returns true, if the policy is managed by Elasticsearch,
+// which is indicated by the metadata field managed.
+func isManagedPolicy(policy types.IlmPolicy) bool {
+	managed, ok := policy.Meta_["managed"]
+	if !ok {
+		return false
+	}
+
+	var isManaged bool
+	_ = json.Unmarshal(managed, &isManaged)
+
+	return isManaged
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_diffPolicies(t *testing.T) {
+	policyA := types.IlmPolicy{
+		Phases: types.Phases{
+			Hot: &types.Phase{
+				MinAge: "0ms",
+				Actions: &types.IlmActions{
+					Rollover: &types.RolloverAction{MaxAge: "30d"},
+				},
+			},
+			Warm: &types.Phase{
+				MinAge: "7d",
+				Actions: &types.IlmActions{
+					Forcemerge: &types.ForceMergeAction{MaxNumSegments: 1},
+					Readonly:   &types.EmptyObject{},
+				},
+			},
+			Delete: &types.Phase{
+				MinAge: "90d",
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		policyB types.IlmPolicy
+
+		wantDifferences []policyDifference
+	}{
+		{
+			name:    "identical",
+			policyB: policyA,
+
+			wantDifferences: []policyDifference{},
+		},
+		{
+			name: "different",
+			policyB: types.IlmPolicy{
+				Phases: types.Phases{
+					Hot: &types.Phase{
+						MinAge: "0ms",
+						Actions: &types.IlmActions{
+							Rollover: &types.RolloverAction{MaxAge: "7d", MaxPrimaryShardSize: "50gb"},
+						},
+					},
+					Warm: &types.Phase{
+						MinAge: "7d",
+						Actions: &types.IlmActions{
+							Forcemerge: &types.ForceMergeAction{MaxNumSegments: 2},
+						},
+					},
+					Delete: &types.Phase{
+						MinAge: "30d",
+					},
+				},
+			},
+
+			wantDifferences: []policyDifference{
+				{setting: "phases.hot.actions.rollover.max_age", a: "30d", b: "7d"},
+				{setting: "phases.hot.actions.rollover.max_primary_shard_size", a: "-", b: "50gb"},
+				{setting: "phases.warm.actions.forcemerge.max_num_segments", a: "1", b: "2"},
+				{setting: "phases.warm.actions.readonly", a: "{}", b: "-"},
+				{setting: "phases.delete.min_age", a: "90d", b: "30d"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			differences, err := diffPolicies(policyA, tc.policyB)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantDifferences, differences)
+		})
+	}
+}
+
+func Test_marshalPolicy(t *testing.T) {
+	policy := types.IlmPolicy{
+		Phases: types.Phases{
+			Hot: &types.Phase{
+				MinAge: "0ms",
+				Actions: &types.IlmActions{
+					Rollover: &types.RolloverAction{MaxAge: "30d", MaxPrimaryShardSize: "50gb"},
+				},
+			},
+			Delete: &types.Phase{
+				MinAge: "90d",
+				Actions: &types.IlmActions{
+					Delete: &types.DeleteAction{},
+				},
+			},
+		},
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			body, err := marshalPolicy(policy, format)
+			require.NoError(t, err)
+
+			gotPolicy, err := unmarshalPolicy(body, format)
+			require.NoError(t, err)
+
+			gotBody, err := marshalPolicy(gotPolicy, format)
+			require.NoError(t, err)
+			require.Equal(t, string(body), string(gotBody))
+
+			differences, err := diffPolicies(policy, gotPolicy)
+			require.NoError(t, err)
+			require.Empty(t, differences)
+		})
+	}
+}
+
+func Test_marshalPolicy_defaults(t *testing.T) {
+	filePolicy, err := unmarshalPolicy([]byte(`
+phases:
+  hot:
+    actions:
+      rollover:
+        max_age: 30d
+  warm:
+    min_age: 7d
+    actions:
+      migrate: {}
+  delete:
+    min_age: 90d
+    actions:
+      delete: {}
+`), "yaml")
+	require.NoError(t, err)
+
+	clusterPolicy, err := unmarshalPolicy([]byte(`{"phases": {
+		"hot": {"min_age": "0ms", "actions": {"rollover": {"max_age": "30d"}}},
+		"warm": {"min_age": "7d", "actions": {"migrate": {"enabled": true}}},
+		"delete": {"min_age": "90d", "actions": {"delete": {"delete_searchable_snapshot": true}}}
+	}}`), "json")
+	require.NoError(t, err)
+
+	desired, err := marshalPolicy(filePolicy, "json")
+	require.NoError(t, err)
+
+	current, err := marshalPolicy(clusterPolicy, "json")
+	require.NoError(t, err)
+	require.Equal(t, string(current), string(desired))
+
+	clusterPolicy.Phases.Delete.Actions.Delete.DeleteSearchableSnapshot = new(bool)
+	current, err = marshalPolicy(clusterPolicy, "json")
+	require.NoError(t, err)
+	require.NotEqual(t, string(current), string(desired))
+}
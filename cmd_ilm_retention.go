@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/urfave/cli/v3"
+)
+
+func ilmRetention(ctx context.Context, cmd *cli.Command) error {
+	ilmPolicy := cmd.String("ilm-policy")
+	format := cmd.String("format")
+
+	newDeleteMinAge, err := parseESDuration(cmd.String("delete-min-age"))
+	if err != nil {
+		return fmt.Errorf("failed to parse delete min age: %w", err)
+	}
+
+	client, err := newTypedClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	policies, err := client.Ilm.GetLifecycle().Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	deleteMinAges := make(map[string]time.Duration, len(policies))
+	for name, policy := range policies {
+		if policy.Policy.Phases.Delete == nil {
+			continue
+		}
+
+		deleteMinAges[name], err = phaseMinAge(policy.Policy.Phases.Delete)
+		if err != nil {
+			return fmt.Errorf("failed to parse min_age of delete phase of policy %q: %w", name, err)
+		}
+	}
+
+	_, totalSizes, err := getIndexSizes(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	backing, err := getBackingIndices(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	ilms, err := client.Ilm.ExplainLifecycle("_all").OnlyManaged(true).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	indices := make([]retentionIndex, 0, len(ilms.Indices))
+	for index, ilm := range ilms.Indices {
+		managed, ok := ilm.(*types.LifecycleExplainManaged)
+		if !ok {
+			continue
+		}
+
+		if ilmPolicy != "" && ilmPolicy != *managed.Policy {
+			continue
+		}
+
+		age, err := parseESDuration(managed.Age)
+		if err != nil {
+			return err
+		}
+
+		indices = append(indices, retentionIndex{
+			policy:     *managed.Policy,
+			phase:      *managed.Phase,
+			step:       deref(managed.Step),
+			writeIndex: backing[index].writeIndex,
+			age:        age,
+			totalSize:  totalSizes[index],
+		})
+	}
+
+	impact := calcRetentionImpact(deleteMinAges, indices, newDeleteMinAge, cmd.Bool("add-delete-phase"))
+
+	fmt.Fprintf(cmd.Writer, "Projected impact of delete min_age %s per policy:\n", formatDuration(newDeleteMinAge))
+
+	data := [][]string{}
+	for policyName, policy := range mapOrderedByKey(impact) {
+		deleteMinAge := "-"
+		if policy.hasDeletePhase {
+			deleteMinAge = formatDuration(policy.deleteMinAge)
+		}
+
+		for _, phaseName := range sortedPhases(policy.phases) {
+			phase := policy.phases[phaseName]
+			data = append(data, []string{policyName, deleteMinAge, phaseName, strconv.Itoa(phase.indices), units.BytesSize(float64(phase.totalSize)), strconv.Itoa(phase.deletedIndices), units.BytesSize(float64(phase.freedSize))})
+		}
+	}
+
+	table := newTable(cmd.Writer, format)
+	table.Header([]string{
+		"Policy", "Delete Min Age", "Phase", "Indices", "Total Size", "Deleted Indices", "Freed Size",
+	})
+	err = table.Bulk(data)
+	if err != nil {
+		return err
+	}
+
+	err = table.Render()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Writer, "\nProjected space freed per tier:\n")
+
+	tiers := impact.PerTier()
+	data = make([][]string, 0, len(tiers))
+	for _, tierName := range sortedPhases(tiers) {
+		tier := tiers[tierName]
+
+		freedPct := 0.0
+		if tier.totalSize > 0 {
+			freedPct = 100.0 / float64(tier.totalSize) * float64(tier.freedSize)
+		}
+
+		data = append(data, []string{tierName, strconv.Itoa(tier.indices), units.BytesSize(float64(tier.totalSize)), strconv.Itoa(tier.deletedIndices), units.BytesSize(float64(tier.freedSize)), fmt.Sprintf("%.1f%%", freedPct)})
+	}
+
+	table = newTable(cmd.Writer, format)
+	table.Header([]string{
+		"Tier", "Indices", "Total Size", "Deleted Indices", "Freed Size", "Freed %",
+	})
+	err = table.Bulk(data)
+	if err != nil {
+		return err
+	}
+
+	return table.Render()
+}
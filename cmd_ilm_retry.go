@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/urfave/cli/v3"
+)
+
+func ilmRetry(ctx context.Context, cmd *cli.Command) error {
+	client, err := newTypedClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	return retryFailedIndices(ctx, cmd, client)
+}
+
+// retryFailedIndices retries the failed step of the indices in the ERROR step
+// selected by the flags of cmd and reports the result after the recheck
+// delay.
+func retryFailedIndices(ctx context.Context, cmd *cli.Command, client *elasticsearch.TypedClient) error {
+	dryRun := cmd.Bool("dry-run")
+	indexPattern := cmd.String("index-pattern")
+	ilmPolicy := cmd.String("ilm-policy")
+	phase := cmd.String("phase")
+	failedStep := cmd.String("failed-step")
+	recheckDelay := cmd.Duration("recheck-delay")
+	format := cmd.String("format")
+
+	var dryRunPrefix string
+	if dryRun {
+		dryRunPrefix = "(DRY RUN) "
+	}
+
+	ilms, err := client.Ilm.ExplainLifecycle(indexPattern).OnlyManaged(true).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	candidates := retryCandidates(ilms.Indices, retryFilter{policy: ilmPolicy, phase: phase, failedStep: failedStep})
+	if len(candidates) == 0 {
+		fmt.Fprintf(cmd.Writer, "No indices in ERROR step found\n")
+		return nil
+	}
+
+	var retryFailed int
+	retried := map[string]string{}
+	for _, managed := range candidates {
+		fmt.Fprintf(cmd.Writer, "%sretry %q (phase: %q, action: %q, failed step: %q, policy: %q)\n", dryRunPrefix, managed.Index, deref(managed.Phase), deref(managed.Action), deref(managed.FailedStep), deref(managed.Policy))
+		if dryRun {
+			continue
+		}
+
+		resp, err := client.Ilm.Retry(managed.Index).Do(ctx)
+		if err != nil {
+			retryFailed++
+			fmt.Fprintf(cmd.Writer, "retry of %q failed: %v\n", managed.Index, err)
+			continue
+		}
+
+		if !resp.Acknowledged {
+			retryFailed++
+			fmt.Fprintf(cmd.Writer, "retry of %q has not been acknowledged\n", managed.Index)
+			continue
+		}
+
+		retried[managed.Index] = deref(managed.FailedStep)
+	}
+
+	var retryErr error
+	if retryFailed > 0 {
+		retryErr = fmt.Errorf("retry failed for %d of %d indices", retryFailed, len(candidates))
+	}
+
+	if len(retried) == 0 {
+		return retryErr
+	}
+
+	fmt.Fprintf(cmd.Writer, "\nwaiting %s before re-checking the retried indices\n", recheckDelay)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(recheckDelay):
+	}
+
+	ilms, err = client.Ilm.ExplainLifecycle(indexPattern).OnlyManaged(true).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(retried))
+	for name := range retried {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var recovered, stillFailing int
+	data := make([][]string, 0, len(retried))
+	for _, name := range names {
+		result := classifyRetryResult(ilms.Indices[name])
+		switch result {
+		case retryRecovered:
+			recovered++
+		case retryStillFailing:
+			stillFailing++
+		}
+
+		var phase, action, step string
+		managed, ok := ilms.Indices[name].(*types.LifecycleExplainManaged)
+		if ok {
+			phase, action, step = deref(managed.Phase), deref(managed.Action), deref(managed.Step)
+		}
+
+		resultText := string(result)
+		if ok && result == retryStillFailing {
+			if _, reason := classifyStepInfo(managed.StepInfo); reason != "" {
+				resultText += ": " + reason
+			}
+		}
+
+		data = append(data, []string{name, retried[name], phase, action, step, resultText})
+	}
+
+	table := newTable(cmd.Writer, format)
+	table.Header([]string{
+		"Index", "Failed Step", "Phase", "Action", "Step", "Result",
+	})
+	err = table.Bulk(data)
+	if err != nil {
+		return err
+	}
+
+	err = table.Render()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.Writer, "%d of %d retried indices recovered\n", recovered, len(retried))
+
+	if stillFailing > 0 {
+		return errors.Join(retryErr, fmt.Errorf("%d of %d retried indices are still failing", stillFailing, len(retried)))
+	}
+
+	return retryErr
+}
+
+// retryFilter restricts the indices to retry to the given policy, phase and
+// failed step. Empty values match all indices.
+type retryFilter struct {
+	policy     string
+	phase      string
+	failedStep string
+}
+
+// retryCandidates returns the managed indices in the ERROR step matching the
+// filter, sorted by name.
+func retryCandidates(indices map[string]types.LifecycleExplain, filter retryFilter) []*types.LifecycleExplainManaged {
+	var candidates []*types.LifecycleExplainManaged
+	for _, ilm := range indices {
+		managed, ok := ilm.(*types.LifecycleExplainManaged)
+		if !ok {
+			continue
+		}
+
+		if deref(managed.Step) != "ERROR" {
+			continue
+		}
+
+		if filter.policy != "" && filter.policy != deref(managed.Policy) {
+			continue
+		}
+
+		if filter.phase != "" && filter.phase != deref(managed.Phase) {
+			continue
+		}
+
+		if filter.failedStep != "" && filter.failedStep != deref(managed.FailedStep) {
+			continue
+		}
+
+		candidates = append(candidates, managed)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Index < candidates[j].Index
+	})
+
+	return candidates
+}
+
+type retryResult string
+
+const (
+	retryRecovered    retryResult = "recovered"
+	retryStillFailing retryResult = "still failing"
+	retryUnmanaged    retryResult = "no longer managed"
+)
+
+// classifyRetryResult returns the result of the retry of an index based on
+// its ILM explain after the recheck delay.
+func classifyRetryResult(ilm types.LifecycleExplain) retryResult {
+	managed, ok := ilm.(*types.LifecycleExplainManaged)
+	switch {
+	case !ok:
+		return retryUnmanaged
+	case deref(managed.Step) == "ERROR":
+		return retryStillFailing
+	default:
+		return retryRecovered
+	}
+}
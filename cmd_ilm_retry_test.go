@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func Test_retryCandidates(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+
+	indices := map[string]types.LifecycleExplain{
+		"logs-3": &types.LifecycleExplainManaged{
+			Index: "logs-3", Policy: ptr("logs"), Phase: ptr("warm"), Step: ptr("ERROR"), FailedStep: ptr("forcemerge"),
+		},
+		"logs-1": &types.LifecycleExplainManaged{
+			Index: "logs-1", Policy: ptr("logs"), Phase: ptr("hot"), Step: ptr("ERROR"), FailedStep: ptr("check-rollover-ready"),
+		},
+		"logs-2": &types.LifecycleExplainManaged{
+			Index: "logs-2", Policy: ptr("logs"), Phase: ptr("warm"), Step: ptr("complete"),
+		},
+		"metrics-1": &types.LifecycleExplainManaged{
+			Index: "metrics-1", Policy: ptr("metrics"), Phase: ptr("warm"), Step: ptr("ERROR"), FailedStep: ptr("forcemerge"),
+		},
+		"unmanaged": &types.LifecycleExplainUnmanaged{Index: "unmanaged"},
+	}
+
+	tests := []struct {
+		name   string
+		filter retryFilter
+
+		want []string
+	}{
+		{
+			name: "all failed indices",
+
+			want: []string{"logs-1", "logs-3", "metrics-1"},
+		},
+		{
+			name:   "policy",
+			filter: retryFilter{policy: "logs"},
+
+			want: []string{"logs-1", "logs-3"},
+		},
+		{
+			name:   "phase",
+			filter: retryFilter{phase: "warm"},
+
+			want: []string{"logs-3", "metrics-1"},
+		},
+		{
+			name:   "failed step",
+			filter: retryFilter{failedStep: "check-rollover-ready"},
+
+			want: []string{"logs-1"},
+		},
+		{
+			name:   "policy and failed step",
+			filter: retryFilter{policy: "metrics", failedStep: "check-rollover-ready"},
+
+			want: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := []string{}
+			for _, managed := range retryCandidates(indices, tc.filter) {
+				got = append(got, managed.Index)
+			}
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func Test_classifyRetryResult(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name string
+		ilm  types.LifecycleExplain
+
+		want retryResult
+	}{
+		{
+			name: "recovered",
+			ilm:  &types.LifecycleExplainManaged{Index: "idx", Phase: ptr("warm"), Step: ptr("forcemerge")},
+
+			want: retryRecovered,
+		},
+		{
+			name: "still failing",
+			ilm:  &types.LifecycleExplainManaged{Index: "idx", Phase: ptr("warm"), Step: ptr("ERROR"), FailedStep: ptr("forcemerge")},
+
+			want: retryStillFailing,
+		},
+		{
+			name: "no longer managed",
+			ilm:  &types.LifecycleExplainUnmanaged{Index: "idx"},
+
+			want: retryUnmanaged,
+		},
+		{
+			name: "deleted",
+
+			want: retryUnmanaged,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, classifyRetryResult(tc.ilm))
+		})
+	}
+}
+
+func Test_retryFailedIndices_noFailedIndices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"indices": {"logs-1": {"index": "logs-1", "managed": true, "policy": "logs", "phase": "hot", "action": "rollover", "step": "check-rollover-ready"}}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := elasticsearch.NewTypedClient(elasticsearch.Config{Addresses: []string{server.URL}})
+	require.NoError(t, err)
+
+	w := &bytes.Buffer{}
+	cmd := &cli.Command{
+		Name: "retry",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "index-pattern", Value: "_all"},
+		},
+		Writer: w,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return retryFailedIndices(ctx, cmd, client)
+		},
+	}
+
+	err = cmd.Run(context.Background(), []string{"retry"})
+	require.NoError(t, err)
+	require.Equal(t, "No indices in ERROR step found\n", w.String())
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/urfave/cli/v3"
+)
+
+func ilmSetPolicy(ctx context.Context, cmd *cli.Command) error {
+	dryRun := cmd.Bool("dry-run")
+	indexPattern := cmd.String("index-pattern")
+	newPolicy := cmd.String("policy")
+	remove := cmd.Bool("remove")
+	format := cmd.String("format")
+
+	if (newPolicy == "") == !remove {
+		return fmt.Errorf("exactly one of --policy or --remove is required")
+	}
+
+	err := validateTableFormat(format)
+	if err != nil {
+		return err
+	}
+
+	var dryRunPrefix string
+	if dryRun {
+		dryRunPrefix = "(DRY RUN) "
+	}
+
+	filter, err := newIndexFilter(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := newTypedClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	var policyDefinition types.IlmPolicy
+	if !remove {
+		policies, err := client.Ilm.GetLifecycle().Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		policy, ok := policies[newPolicy]
+		if !ok {
+			return fmt.Errorf("policy %q not found", newPolicy)
+		}
+
+		policyDefinition = policy.Policy
+	}
+
+	indices, err := getIndexDetails(ctx, client, indexPattern, filter)
+	if err != nil {
+		return err
+	}
+
+	if len(indices) == 0 {
+		fmt.Fprintf(cmd.Writer, "no ilm managed indices found matching the given criteria\n")
+		return nil
+	}
+
+	sortIndexDetails(indices, len(filter.phases) != 1, nil)
+
+	err = renderIndexDetails(cmd.Writer, format, indices)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indices {
+		if remove {
+			fmt.Fprintf(cmd.Writer, "%sremove policy %q from %q (phase: %q)\n", dryRunPrefix, index.policy, index.name, index.phase)
+			if dryRun {
+				continue
+			}
+
+			resp, err := client.Ilm.RemovePolicy(index.name).Do(ctx)
+			if err != nil {
+				return err
+			}
+
+			if resp.HasFailures {
+				return fmt.Errorf("remove policy operation for %q failed", index.name)
+			}
+
+			continue
+		}
+
+		if index.policy == newPolicy {
+			fmt.Fprintf(cmd.Writer, "index %q already uses policy %q, skipping\n", index.name, newPolicy)
+			continue
+		}
+
+		if index.phase != "new" && policyPhase(policyDefinition, index.phase) == nil {
+			fmt.Fprintf(cmd.Writer, "WARNING: policy %q does not define phase %q of index %q, the index continues with the cached phase definition of its current policy until it moves to a phase defined in %q\n", newPolicy, index.phase, index.name, newPolicy)
+		}
+
+		fmt.Fprintf(cmd.Writer, "%sset policy of %q (phase: %q) from %q to %q\n", dryRunPrefix, index.name, index.phase, index.policy, newPolicy)
+		if dryRun {
+			continue
+		}
+
+		resp, err := client.Indices.PutSettings().Indices(index.name).Lifecycle(&types.IndexSettingsLifecycle{
+			Name: &newPolicy,
+		}).Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Acknowledged {
+			return fmt.Errorf("set policy operation for %q has not been acknowledged", index.name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/urfave/cli/v3"
+)
+
+const causeLongRunning = "long running step"
+
+// stuckIndex contains the details of an ILM managed index, which is stuck in
+// ILM, together with the root cause derived from the step info.
+type stuckIndex struct {
+	name       string
+	policy     string
+	phase      string
+	action     string
+	step       string
+	failedStep string
+	retries    int
+	timeInStep time.Duration
+	cause      string
+	reason     string
+}
+
+// stuckCauses maps substrings of the failure reason reported by ILM in the
+// step info to the root cause. The first matching entry wins.
+var stuckCauses = []struct {
+	contains []string
+	cause    string
+}{
+	{contains: []string{"rollover_alias", "rollover alias"}, cause: "rollover alias missing"},
+	{contains: []string{"repository"}, cause: "snapshot repository missing"},
+	{contains: []string{"policy"}, cause: "policy missing"},
+	{contains: []string{"watermark", "disk"}, cause: "disk watermark"},
+	{contains: []string{"shard", "allocat", "node"}, cause: "shard allocation"},
+	{contains: []string{"block", "read-only", "read only"}, cause: "index blocked"},
+	{contains: []string{"snapshot"}, cause: "snapshot failed"},
+}
+
+func ilmStuck(ctx context.Context, cmd *cli.Command) error {
+	indexPattern := cmd.String("index-pattern")
+	ilmPolicy := cmd.String("ilm-policy")
+	format := cmd.String("format")
+
+	threshold, err := parseESDuration(cmd.String("threshold"))
+	if err != nil {
+		return fmt.Errorf("failed to parse threshold: %w", err)
+	}
+
+	client, err := newTypedClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ilms, err := client.Ilm.ExplainLifecycle(indexPattern).OnlyManaged(true).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	stuck := make([]stuckIndex, 0)
+	for _, ilm := range ilms.Indices {
+		managed, ok := ilm.(*types.LifecycleExplainManaged)
+		if !ok {
+			continue
+		}
+
+		if ilmPolicy != "" && ilmPolicy != deref(managed.Policy) {
+			continue
+		}
+
+		index, ok := findStuckIndex(managed, threshold, now)
+		if !ok {
+			continue
+		}
+
+		stuck = append(stuck, index)
+	}
+
+	if len(stuck) == 0 {
+		fmt.Fprintf(cmd.Writer, "No stuck indices found\n")
+		return nil
+	}
+
+	sort.Slice(stuck, func(i, j int) bool {
+		if stuck[i].cause != stuck[j].cause {
+			return stuck[i].cause < stuck[j].cause
+		}
+
+		return stuck[i].name < stuck[j].name
+	})
+
+	causeCounts := make(map[string]int)
+	for _, index := range stuck {
+		causeCounts[index.cause]++
+	}
+
+	fmt.Fprintf(cmd.Writer, "Stuck indices by cause:\n")
+	for cause, count := range mapOrderedByKey(causeCounts) {
+		fmt.Fprintf(cmd.Writer, "  %s: %d\n", cause, count)
+	}
+
+	fmt.Fprintln(cmd.Writer)
+
+	data := make([][]string, 0, len(stuck))
+	for _, index := range stuck {
+		data = append(data, []string{index.cause, index.name, index.policy, index.phase, index.action, index.step, index.failedStep, strconv.Itoa(index.retries), formatDuration(index.timeInStep), index.reason})
+	}
+
+	table := newTable(cmd.Writer, format)
+	table.Header([]string{
+		"Cause", "Index", "Policy", "Phase", "Action", "Step", "Failed Step", "Retries", "Time in Step", "Reason",
+	})
+	err = table.Bulk(data)
+	if err != nil {
+		return err
+	}
+
+	return table.Render()
+}
+
+// findStuckIndex returns the stuckIndex for an ILM managed index and true,
+// if the index is stuck in ILM. An index is considered stuck, if it is in the
+// ERROR step, if it has failed step retries or if it is in the same step for
+// longer than threshold. Indices waiting in the steps complete or
+// check-rollover-ready are not considered stuck, since waiting there for a
+// long time is expected.
+func findStuckIndex(managed *types.LifecycleExplainManaged, threshold time.Duration, now time.Time) (stuckIndex, bool) {
+	index := stuckIndex{
+		name:       managed.Index,
+		policy:     deref(managed.Policy),
+		phase:      deref(managed.Phase),
+		action:     deref(managed.Action),
+		step:       deref(managed.Step),
+		failedStep: deref(managed.FailedStep),
+		retries:    deref(managed.FailedStepRetryCount),
+	}
+
+	if managed.StepTimeMillis != nil {
+		index.timeInStep = now.Sub(time.UnixMilli(*managed.StepTimeMillis))
+	}
+
+	failed := index.step == "ERROR" || index.retries > 0
+	waiting := index.step == "complete" || index.step == "check-rollover-ready"
+	longRunning := threshold > 0 && index.timeInStep > threshold && !waiting
+	if !failed && !longRunning {
+		return stuckIndex{}, false
+	}
+
+	index.cause, index.reason = classifyStepInfo(managed.StepInfo)
+	if !failed && index.cause == "unknown" {
+		index.cause = causeLongRunning
+	}
+
+	return index, true
+}
+
+// classifyStepInfo returns the root cause and the reason from the step info
+// reported by ILM for an index. If the cause can not be determined from the
+// reason, "unknown" is returned as cause.
+func classifyStepInfo(stepInfo map[string]json.RawMessage) (cause string, reason string) {
+	reasons := make([]string, 0, 2)
+	for _, key := range []string{"reason", "message"} {
+		var value string
+		if err := json.Unmarshal(stepInfo[key], &value); err == nil && value != "" {
+			reasons = append(reasons, value)
+		}
+	}
+
+	var causedBy struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(stepInfo["caused_by"], &causedBy); err == nil && causedBy.Reason != "" && !slices.Contains(reasons, causedBy.Reason) {
+		reasons = append(reasons, causedBy.Reason)
+	}
+
+	reason = strings.Join(reasons, ": ")
+
+	lowerReason := strings.ToLower(reason)
+	for _, stuckCause := range stuckCauses {
+		for _, contains := range stuckCause.contains {
+			if strings.Contains(lowerReason, contains) {
+				return stuckCause.cause, reason
+			}
+		}
+	}
+
+	return "unknown", reason
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_findStuckIndex(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	ptr := func(s string) *string { return &s }
+	stepTime := func(d time.Duration) *int64 {
+		ms := now.Add(-d).UnixMilli()
+		return &ms
+	}
+
+	tests := []struct {
+		name    string
+		managed types.LifecycleExplainManaged
+
+		wantStuck bool
+		wantCause string
+	}{
+		{
+			name: "complete",
+			managed: types.LifecycleExplainManaged{
+				Index: "idx", Phase: ptr("warm"), Action: ptr("complete"), Step: ptr("complete"), StepTimeMillis: stepTime(10 * day),
+			},
+
+			wantStuck: false,
+		},
+		{
+			name: "waiting for rollover",
+			managed: types.LifecycleExplainManaged{
+				Index: "idx", Phase: ptr("hot"), Action: ptr("rollover"), Step: ptr("check-rollover-ready"), StepTimeMillis: stepTime(10 * day),
+			},
+
+			wantStuck: false,
+		},
+		{
+			name: "error",
+			managed: types.LifecycleExplainManaged{
+				Index: "idx", Phase: ptr("hot"), Action: ptr("rollover"), Step: ptr("ERROR"), FailedStep: ptr("check-rollover-ready"), StepTimeMillis: stepTime(time.Hour),
+				StepInfo: map[string]json.RawMessage{
+					"type":   json.RawMessage(`"illegal_argument_exception"`),
+					"reason": json.RawMessage(`"setting [index.lifecycle.rollover_alias] for index [idx] is empty or not defined"`),
+				},
+			},
+
+			wantStuck: true,
+			wantCause: "rollover alias missing",
+		},
+		{
+			name: "long running allocation",
+			managed: types.LifecycleExplainManaged{
+				Index: "idx", Phase: ptr("warm"), Action: ptr("allocate"), Step: ptr("check-allocation"), StepTimeMillis: stepTime(2 * day),
+				StepInfo: map[string]json.RawMessage{
+					"message": json.RawMessage(`"Waiting for all shard copies to be active"`),
+				},
+			},
+
+			wantStuck: true,
+			wantCause: "shard allocation",
+		},
+		{
+			name: "long running without step info",
+			managed: types.LifecycleExplainManaged{
+				Index: "idx", Phase: ptr("warm"), Action: ptr("forcemerge"), Step: ptr("forcemerge"), StepTimeMillis: stepTime(2 * day),
+			},
+
+			wantStuck: true,
+			wantCause: causeLongRunning,
+		},
+		{
+			name: "short running",
+			managed: types.LifecycleExplainManaged{
+				Index: "idx", Phase: ptr("warm"), Action: ptr("forcemerge"), Step: ptr("forcemerge"), StepTimeMillis: stepTime(time.Hour),
+			},
+
+			wantStuck: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			index, stuck := findStuckIndex(&tc.managed, day, now)
+
+			require.Equal(t, tc.wantStuck, stuck)
+			require.Equal(t, tc.wantCause, index.cause)
+		})
+	}
+}
+
+func Test_classifyStepInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		stepInfo map[string]json.RawMessage
+
+		wantCause  string
+		wantReason string
+	}{
+		{
+			name:     "empty",
+			stepInfo: nil,
+
+			wantCause:  "unknown",
+			wantReason: "",
+		},
+		{
+			name: "repository missing",
+			stepInfo: map[string]json.RawMessage{
+				"type":   json.RawMessage(`"repository_missing_exception"`),
+				"reason": json.RawMessage(`"[found-snapshots] missing"`),
+				"caused_by": json.RawMessage(`{
+					"type": "repository_exception",
+					"reason": "repository [found-snapshots] is missing"
+				}`),
+			},
+
+			wantCause:  "snapshot repository missing",
+			wantReason: "[found-snapshots] missing: repository [found-snapshots] is missing",
+		},
+		{
+			name: "policy missing",
+			stepInfo: map[string]json.RawMessage{
+				"type":   json.RawMessage(`"illegal_argument_exception"`),
+				"reason": json.RawMessage(`"policy [logs] does not exist"`),
+			},
+
+			wantCause:  "policy missing",
+			wantReason: "policy [logs] does not exist",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cause, reason := classifyStepInfo(tc.stepInfo)
+
+			require.Equal(t, tc.wantCause, cause)
+			require.Equal(t, tc.wantReason, reason)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/urfave/cli/v3"
+)
+
+// moveJournal records the original ILM state of the indices moved by ilm move,
+// which allows to move the indices back with ilm undo.
+type moveJournal struct {
+	Deployment string              `json:"deployment"`
+	Region     string              `json:"region"`
+	Created    time.Time           `json:"created"`
+	Target     moveJournalStep     `json:"target"`
+	Indices    []moveJournalRecord `json:"indices"`
+}
+
+// moveJournalStep is the step key of an index in the move journal.
+type moveJournalStep struct {
+	Phase  string `json:"phase"`
+	Action string `json:"action,omitempty"`
+	Step   string `json:"step,omitempty"`
+}
+
+// moveJournalRecord contains the original ILM state of an index before the
+// move.
+type moveJournalRecord struct {
+	Index  string          `json:"index"`
+	Policy string          `json:"policy"`
+	Origin moveJournalStep `json:"origin"`
+}
+
+// defaultJournalFilename returns the name of the journal file used, if no
+// journal file is given explicitly.
+func defaultJournalFilename(deployment string, now time.Time) string {
+	return fmt.Sprintf("ec_check-ilm-move-%s-%s.json", deployment, now.Format("20060102-150405"))
+}
+
+// writeMoveJournal writes the journal for the indices, which are about to be
+// moved to the target, to filename.
+func writeMoveJournal(filename string, deployment string, region string, indices []indexDetails, target moveTarget) error {
+	journal := moveJournal{
+		Deployment: deployment,
+		Region:     region,
+		Created:    time.Now().UTC(),
+		Target:     moveJournalStep{Phase: target.phase, Action: target.action, Step: target.step},
+		Indices:    make([]moveJournalRecord, 0, len(indices)),
+	}
+
+	for _, index := range indices {
+		journal.Indices = append(journal.Indices, moveJournalRecord{
+			Index:  index.name,
+			Policy: index.policy,
+			Origin: moveJournalStep{Phase: index.phase, Action: index.action, Step: index.step},
+		})
+	}
+
+	body, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, append(body, '\n'), 0o644)
+}
+
+// readMoveJournal reads a journal written by writeMoveJournal.
+func readMoveJournal(filename string) (moveJournal, error) {
+	var journal moveJournal
+
+	body, err := os.ReadFile(filename)
+	if err != nil {
+		return journal, err
+	}
+
+	err = json.Unmarshal(body, &journal)
+	if err != nil {
+		return journal, fmt.Errorf("failed to read journal %q: %w", filename, err)
+	}
+
+	return journal, nil
+}
+
+func ilmUndo(ctx context.Context, cmd *cli.Command) error {
+	dryRun := cmd.Bool("dry-run")
+	force := cmd.Bool("force")
+	deployment := cmd.String("deployment")
+
+	var dryRunPrefix string
+	if dryRun {
+		dryRunPrefix = "(DRY RUN) "
+	}
+
+	if cmd.Args().Len() != 1 {
+		return fmt.Errorf("expected the journal file written by ilm move as argument")
+	}
+
+	journal, err := readMoveJournal(cmd.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if journal.Deployment != deployment && !force {
+		return fmt.Errorf("journal has been written for deployment %q, but deployment %q is given, use --force to undo anyway", journal.Deployment, deployment)
+	}
+
+	client, err := newTypedClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ilms, err := client.Ilm.ExplainLifecycle("_all").OnlyManaged(true).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	results := make([]moveResult, 0, len(journal.Indices))
+	for _, record := range journal.Indices {
+		index, rejected, reason := checkUndoCandidate(ilms.Indices, record)
+		if rejected != nil {
+			fmt.Fprintf(cmd.Writer, "%s, skipping\n", reason)
+			results = append(results, *rejected)
+			continue
+		}
+
+		origin := moveTarget{phase: record.Origin.Phase, action: record.Origin.Action, step: record.Origin.Step}
+		if index.policy != record.Policy {
+			fmt.Fprintf(cmd.Writer, "WARNING: policy of index %q changed from %q to %q since the move\n", index.name, record.Policy, index.policy)
+		}
+
+		fmt.Fprintf(cmd.Writer, "%smove %q (phase: %q, action: %q, step: %q) back to %s\n", dryRunPrefix, index.name, index.phase, index.action, index.step, origin)
+		if dryRun {
+			results = append(results, moveResult{index: index.name, result: moveSucceeded})
+			continue
+		}
+
+		err := moveToStep(ctx, client, index, origin)
+		if err != nil {
+			fmt.Fprintf(cmd.Writer, "move of %q failed: %v\n", index.name, err)
+			results = append(results, moveResult{index: index.name, result: moveFailed, details: err.Error()})
+			continue
+		}
+
+		results = append(results, moveResult{index: index.name, result: moveSucceeded})
+	}
+
+	return moveSummary(cmd, results)
+}
+
+// checkUndoCandidate returns the current ILM state of the index of a journal
+// record. If the move of the index can not be undone, the returned moveResult
+// contains the result and the details for the summary and reason the
+// explanation for the user. Indices replaced by a mounted searchable snapshot
+// can not be moved back, since the mounted index stays a searchable snapshot.
+func checkUndoCandidate(indices map[string]types.LifecycleExplain, record moveJournalRecord) (indexDetails, *moveResult, string) {
+	managed := explainMovedIndex(indices, record.Index)
+	if managed == nil {
+		return indexDetails{}, &moveResult{index: record.Index, result: moveSkipped, details: "index deleted or not managed"},
+			fmt.Sprintf("index %q does not exist or is not managed by ILM anymore", record.Index)
+	}
+
+	if managed.Index != record.Index {
+		return indexDetails{}, &moveResult{index: record.Index, result: moveSkipped, details: fmt.Sprintf("replaced by searchable snapshot %q", managed.Index)},
+			fmt.Sprintf("index %q has been replaced by the searchable snapshot %q, which can not be moved back", record.Index, managed.Index)
+	}
+
+	index := indexDetails{
+		name:   record.Index,
+		phase:  deref(managed.Phase),
+		action: deref(managed.Action),
+		step:   deref(managed.Step),
+		policy: deref(managed.Policy),
+	}
+
+	if index.phase == record.Origin.Phase && index.action == record.Origin.Action && index.step == record.Origin.Step {
+		return index, &moveResult{index: index.name, result: moveSkipped, details: "already in original step"},
+			fmt.Sprintf("index %q is already in its original step", index.name)
+	}
+
+	return index, nil, ""
+}
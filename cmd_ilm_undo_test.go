@@ -0,0 +1,105 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_moveJournal(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "journal.json")
+
+	indices := []indexDetails{
+		{name: "logs-1", phase: "hot", action: "complete", step: "complete", policy: "logs"},
+		{name: "logs-2", phase: "warm", action: "forcemerge", step: "segment-count", policy: "logs"},
+	}
+
+	err := writeMoveJournal(filename, "deployment", "region", indices, moveTarget{phase: "cold"})
+	require.NoError(t, err)
+
+	journal, err := readMoveJournal(filename)
+	require.NoError(t, err)
+
+	require.Equal(t, "deployment", journal.Deployment)
+	require.Equal(t, "region", journal.Region)
+	require.Equal(t, moveJournalStep{Phase: "cold"}, journal.Target)
+	require.Equal(t, []moveJournalRecord{
+		{Index: "logs-1", Policy: "logs", Origin: moveJournalStep{Phase: "hot", Action: "complete", Step: "complete"}},
+		{Index: "logs-2", Policy: "logs", Origin: moveJournalStep{Phase: "warm", Action: "forcemerge", Step: "segment-count"}},
+	}, journal.Indices)
+}
+
+func Test_defaultJournalFilename(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.Equal(t, "ec_check-ilm-move-prod-20250102-030405.json", defaultJournalFilename("prod", now))
+}
+
+func Test_checkUndoCandidate(t *testing.T) {
+	ptr := func(s string) *string { return &s }
+
+	indices := map[string]types.LifecycleExplain{
+		"logs-1": &types.LifecycleExplainManaged{
+			Index: "logs-1", Policy: ptr("logs"), Phase: ptr("cold"), Action: ptr("complete"), Step: ptr("complete"),
+		},
+		"restored-logs-2": &types.LifecycleExplainManaged{
+			Index: "restored-logs-2", Policy: ptr("logs"), Phase: ptr("cold"), Action: ptr("complete"), Step: ptr("complete"),
+		},
+		"logs-3": &types.LifecycleExplainManaged{
+			Index: "logs-3", Policy: ptr("logs"), Phase: ptr("warm"), Action: ptr("complete"), Step: ptr("complete"),
+		},
+	}
+
+	origin := moveJournalStep{Phase: "warm", Action: "complete", Step: "complete"}
+
+	tests := []struct {
+		name   string
+		record moveJournalRecord
+
+		wantPhase   string
+		wantDetails string
+	}{
+		{
+			name:   "moved",
+			record: moveJournalRecord{Index: "logs-1", Policy: "logs", Origin: origin},
+
+			wantPhase: "cold",
+		},
+		{
+			name:   "replaced by searchable snapshot",
+			record: moveJournalRecord{Index: "logs-2", Policy: "logs", Origin: origin},
+
+			wantDetails: `replaced by searchable snapshot "restored-logs-2"`,
+		},
+		{
+			name:   "already in original step",
+			record: moveJournalRecord{Index: "logs-3", Policy: "logs", Origin: origin},
+
+			wantDetails: "already in original step",
+		},
+		{
+			name:   "deleted",
+			record: moveJournalRecord{Index: "logs-4", Policy: "logs", Origin: origin},
+
+			wantDetails: "index deleted or not managed",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			index, rejected, _ := checkUndoCandidate(indices, tc.record)
+			if tc.wantDetails != "" {
+				require.NotNil(t, rejected)
+				require.Equal(t, moveSkipped, rejected.result)
+				require.Equal(t, tc.wantDetails, rejected.details)
+				return
+			}
+
+			require.Nil(t, rejected)
+			require.Equal(t, tc.wantPhase, index.phase)
+		})
+	}
+}
@@ -2,12 +2,12 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/docker/go-units"
 	"github.com/urfave/cli/v3"
 )
 
@@ -19,31 +19,24 @@ func listProfiles(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("region %q is not a known Elastic Cloud region", region)
 	}
 
-	deploymentTemplatesURL := fmt.Sprintf("https://api.elastic-cloud.com/api/v1/deployments/templates?region=%s", region)
-
-	resp, err := http.Get(deploymentTemplatesURL)
+	format := cmd.String("format")
+	err := validateOutputFormat(format)
 	if err != nil {
 		return err
 	}
 
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+	if cmd.IsSet("format") && !cmd.Bool("details") {
+		return fmt.Errorf("format is only supported together with --details")
 	}
 
-	var deploymentTemplates DeploymentTemplates
-	err = json.Unmarshal(body, &deploymentTemplates)
+	deploymentTemplates, err := loadDeploymentTemplates(ctx, cmd.ErrWriter, region, templateSourceFromCommand(cmd))
 	if err != nil {
 		return err
 	}
 
-	sort.Slice(deploymentTemplates, func(i, j int) bool {
-		return deploymentTemplates[i].ID < deploymentTemplates[j].ID
-	})
+	if cmd.Bool("details") {
+		return renderColumns(cmd.Writer, format, profileInstanceConfigurationColumns, profileInstanceConfigurations(deploymentTemplates))
+	}
 
 	fmt.Fprintf(cmd.Writer, "Profiles for %q:\n", region)
 	for _, dt := range deploymentTemplates {
@@ -52,3 +45,164 @@ func listProfiles(ctx context.Context, cmd *cli.Command) error {
 
 	return nil
 }
+
+// profileInstanceConfiguration is an instance configuration of the tier of a
+// deployment template (profile).
+type profileInstanceConfiguration struct {
+	profile string
+	tier    string
+	InstanceConfiguration
+}
+
+// instanceConfigurationTier returns the tier of an instance configuration. For
+// Elasticsearch data nodes, this is the data tier (hot, warm, cold, frozen),
+// for other Elasticsearch nodes the node types (e.g. master or ml) and the
+// instance type (e.g. kibana) for all other instances.
+func instanceConfigurationTier(ic InstanceConfiguration) string {
+	tierMatches := tierRegexp.FindStringSubmatch(ic.ID)
+	if len(tierMatches) == 2 {
+		return tierMatches[1]
+	}
+
+	if ic.InstanceType == "elasticsearch" && len(ic.NodeTypes) > 0 {
+		return strings.Join(ic.NodeTypes, ",")
+	}
+
+	return ic.InstanceType
+}
+
+// profileInstanceConfigurations returns the instance configurations of all
+// deployment templates sorted by profile and tier, data tiers first.
+func profileInstanceConfigurations(deploymentTemplates DeploymentTemplates) []profileInstanceConfiguration {
+	var configurations []profileInstanceConfiguration
+	for _, dt := range deploymentTemplates {
+		for _, ic := range dt.InstanceConfigurations {
+			configurations = append(configurations, profileInstanceConfiguration{
+				profile:               dt.ID,
+				tier:                  instanceConfigurationTier(ic),
+				InstanceConfiguration: ic,
+			})
+		}
+	}
+
+	sort.SliceStable(configurations, func(i, j int) bool {
+		a, b := configurations[i], configurations[j]
+		switch {
+		case a.profile != b.profile:
+			return a.profile < b.profile
+		case a.tier != b.tier:
+			_, aIsPhase := phaseOrder[a.tier]
+			_, bIsPhase := phaseOrder[b.tier]
+			if aIsPhase != bIsPhase {
+				return aIsPhase
+			}
+
+			if aIsPhase {
+				return phaseLess(a.tier, b.tier)
+			}
+
+			return a.tier < b.tier
+		}
+
+		return a.ID < b.ID
+	})
+
+	return configurations
+}
+
+// formatSizesMiB formats sizes given in MiB as human readable sizes.
+func formatSizesMiB(sizes []int) string {
+	formatted := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		formatted = append(formatted, units.BytesSize(float64(size)*mibMultiplier))
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// profileInstanceConfigurationColumns are the columns of the instance
+// configurations. The sizes are given in MiB in the machine readable formats.
+// Max Node Disk is the disk size of the largest node, only available if the
+// sizes are given as memory.
+var profileInstanceConfigurationColumns = []column[profileInstanceConfiguration]{
+	{
+		header: "Profile", key: "profile",
+		value: func(c profileInstanceConfiguration) string { return c.profile },
+		raw:   func(c profileInstanceConfiguration) any { return c.profile },
+	},
+	{
+		header: "Tier", key: "tier",
+		value: func(c profileInstanceConfiguration) string { return c.tier },
+		raw:   func(c profileInstanceConfiguration) any { return c.tier },
+	},
+	{
+		header: "Instance Configuration", key: "instance_configuration",
+		value: func(c profileInstanceConfiguration) string { return c.ID },
+		raw:   func(c profileInstanceConfiguration) any { return c.ID },
+	},
+	{
+		header: "Instance Type", key: "instance_type",
+		value: func(c profileInstanceConfiguration) string { return c.InstanceType },
+		raw:   func(c profileInstanceConfiguration) any { return c.InstanceType },
+	},
+	{
+		header: "Storage Multiplier", key: "storage_multiplier",
+		value: func(c profileInstanceConfiguration) string {
+			return strconv.FormatFloat(c.StorageMultiplier, 'f', -1, 64)
+		},
+		raw: func(c profileInstanceConfiguration) any { return c.StorageMultiplier },
+	},
+	{
+		header: "CPU Multiplier", key: "cpu_multiplier",
+		value: func(c profileInstanceConfiguration) string { return strconv.FormatFloat(c.CPUMultiplier, 'f', -1, 64) },
+		raw:   func(c profileInstanceConfiguration) any { return c.CPUMultiplier },
+	},
+	{
+		header: "Resource", key: "resource",
+		value: func(c profileInstanceConfiguration) string { return c.DiscreteSizes.Resource },
+		raw:   func(c profileInstanceConfiguration) any { return c.DiscreteSizes.Resource },
+	},
+	{
+		header: "Sizes", key: "sizes_mib",
+		value: func(c profileInstanceConfiguration) string { return formatSizesMiB(c.DiscreteSizes.Sizes) },
+		raw:   func(c profileInstanceConfiguration) any { return c.DiscreteSizes.Sizes },
+	},
+	{
+		header: "Default Size", key: "default_size_mib",
+		value: func(c profileInstanceConfiguration) string {
+			return units.BytesSize(float64(c.DiscreteSizes.DefaultSize) * mibMultiplier)
+		},
+		raw: func(c profileInstanceConfiguration) any { return c.DiscreteSizes.DefaultSize },
+	},
+	{
+		header: "Max Node Disk", key: "max_node_disk_mib",
+		value: func(c profileInstanceConfiguration) string {
+			disk, ok := maxNodeDiskMiB(c.InstanceConfiguration)
+			if !ok {
+				return "-"
+			}
+
+			return units.BytesSize(disk * mibMultiplier)
+		},
+		raw: func(c profileInstanceConfiguration) any {
+			disk, ok := maxNodeDiskMiB(c.InstanceConfiguration)
+			if !ok {
+				return nil
+			}
+
+			return disk
+		},
+	},
+}
+
+// maxNodeDiskMiB returns the disk size in MiB of the largest node of the
+// instance configuration. ok is false, if the sizes are not given as memory or
+// the instance configuration has no storage multiplier.
+func maxNodeDiskMiB(ic InstanceConfiguration) (disk float64, ok bool) {
+	sizes := ic.DiscreteSizes.Sizes
+	if len(sizes) == 0 || ic.StorageMultiplier == 0 || ic.DiscreteSizes.Resource != "memory" {
+		return 0, false
+	}
+
+	return float64(sizes[len(sizes)-1]) * ic.StorageMultiplier, true
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/docker/go-units"
+	"github.com/urfave/cli/v3"
+)
+
+func compareProfiles(ctx context.Context, cmd *cli.Command) error {
+	deployment := cmd.String("deployment")
+	region := cmd.String("region")
+	headroomPercent := cmd.Float64("headroom-pct")
+
+	format := cmd.String("format")
+	err := validateOutputFormat(format)
+	if err != nil {
+		return err
+	}
+
+	deploymentURL, err := deploymentURLWithCredentials(deployment, region, cmd.String("username"), cmd.String("password"))
+	if err != nil {
+		return err
+	}
+
+	allocations, err := getAllocationInformation(deploymentURL)
+	if err != nil {
+		return err
+	}
+
+	usage, err := tierDiskUsage(allocations)
+	if err != nil {
+		return err
+	}
+
+	if len(usage) == 0 {
+		return fmt.Errorf("no data nodes found in deployment %q", deployment)
+	}
+
+	deploymentTemplates, err := loadDeploymentTemplates(ctx, cmd.ErrWriter, region, templateSourceFromCommand(cmd))
+	if err != nil {
+		return err
+	}
+
+	options := calcProfileOptions(usage, deploymentTemplates, headroomPercent)
+
+	return renderColumns(cmd.Writer, format, profileTierOptionColumns, options)
+}
+
+// formatOptionSize returns the size in bytes as human readable string or "-",
+// if the tier does not fit into the profile.
+func formatOptionSize(o profileTierOption, size float64) string {
+	if !o.fits || size == 0 {
+		return "-"
+	}
+
+	return units.BytesSize(size)
+}
+
+// rawOptionSize returns the size in bytes or nil, if the tier does not fit
+// into the profile.
+func rawOptionSize(o profileTierOption, size float64) any {
+	if !o.fits || size == 0 {
+		return nil
+	}
+
+	return int64(size)
+}
+
+// profileTierOptionColumns are the columns of the profile comparison. Sizes
+// are given in bytes in the machine readable formats.
+var profileTierOptionColumns = []column[profileTierOption]{
+	{
+		header: "Profile", key: "profile",
+		value: func(o profileTierOption) string { return o.profile },
+		raw:   func(o profileTierOption) any { return o.profile },
+	},
+	{
+		header: "Tier", key: "tier",
+		value: func(o profileTierOption) string { return string(o.tier) },
+		raw:   func(o profileTierOption) any { return string(o.tier) },
+	},
+	{
+		header: "Nodes", key: "nodes",
+		value: func(o profileTierOption) string { return strconv.Itoa(o.nodes) },
+		raw:   func(o profileTierOption) any { return o.nodes },
+	},
+	{
+		header: "Node Memory", key: "node_memory_bytes",
+		value: func(o profileTierOption) string { return formatOptionSize(o, o.nodeMemory) },
+		raw:   func(o profileTierOption) any { return rawOptionSize(o, o.nodeMemory) },
+	},
+	{
+		header: "Node Disk", key: "node_disk_bytes",
+		value: func(o profileTierOption) string { return formatOptionSize(o, o.nodeDisk) },
+		raw:   func(o profileTierOption) any { return rawOptionSize(o, o.nodeDisk) },
+	},
+	{
+		header: "Memory", key: "memory_bytes",
+		value: func(o profileTierOption) string { return formatOptionSize(o, o.memory) },
+		raw:   func(o profileTierOption) any { return rawOptionSize(o, o.memory) },
+	},
+	{
+		header: "Disk", key: "disk_bytes",
+		value: func(o profileTierOption) string { return formatOptionSize(o, o.disk) },
+		raw:   func(o profileTierOption) any { return rawOptionSize(o, o.disk) },
+	},
+	{
+		header: "Disk Used", key: "disk_used_bytes",
+		value: func(o profileTierOption) string { return units.BytesSize(o.used) },
+		raw:   func(o profileTierOption) any { return int64(o.used) },
+	},
+	{
+		header: "Free %", key: "free_pct",
+		value: func(o profileTierOption) string {
+			if !o.fits {
+				return "-"
+			}
+
+			return fmt.Sprintf("%.1f%%", o.freePct())
+		},
+		raw: func(o profileTierOption) any {
+			if !o.fits {
+				return nil
+			}
+
+			return o.freePct()
+		},
+	},
+	{
+		header: "Fits", key: "fits",
+		value: func(o profileTierOption) string {
+			if o.fits {
+				return "yes"
+			}
+
+			return "no"
+		},
+		raw: func(o profileTierOption) any { return o.fits },
+	},
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_instanceConfigurationTier(t *testing.T) {
+	tests := []struct {
+		name string
+		ic   InstanceConfiguration
+
+		want string
+	}{
+		{
+			name: "data tier",
+			ic:   InstanceConfiguration{ID: "azure.es.datahot.edsv4", InstanceType: "elasticsearch", NodeTypes: []string{"data", "ingest", "master"}},
+			want: "hot",
+		},
+		{
+			name: "master",
+			ic:   InstanceConfiguration{ID: "azure.es.master.fsv2", InstanceType: "elasticsearch", NodeTypes: []string{"master"}},
+			want: "master",
+		},
+		{
+			name: "kibana",
+			ic:   InstanceConfiguration{ID: "azure.kibana.fsv2", InstanceType: "kibana"},
+			want: "kibana",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, instanceConfigurationTier(tc.ic))
+		})
+	}
+}
+
+func Test_profileInstanceConfigurations(t *testing.T) {
+	templates := DeploymentTemplates{
+		{
+			ID: "azure-storage-optimized",
+			InstanceConfigurations: []InstanceConfiguration{
+				{ID: "azure.kibana.fsv2", InstanceType: "kibana"},
+				{ID: "azure.es.datawarm.edsv4", InstanceType: "elasticsearch"},
+				{ID: "azure.es.datahot.lsv3", InstanceType: "elasticsearch"},
+				{ID: "azure.es.master.fsv2", InstanceType: "elasticsearch", NodeTypes: []string{"master"}},
+			},
+		},
+		{
+			ID: "azure-general-purpose",
+			InstanceConfigurations: []InstanceConfiguration{
+				{ID: "azure.es.datahot.ddv4", InstanceType: "elasticsearch"},
+			},
+		},
+	}
+
+	var got []string
+	for _, c := range profileInstanceConfigurations(templates) {
+		got = append(got, c.profile+"/"+c.tier)
+	}
+
+	require.Equal(t, []string{
+		"azure-general-purpose/hot",
+		"azure-storage-optimized/hot",
+		"azure-storage-optimized/warm",
+		"azure-storage-optimized/kibana",
+		"azure-storage-optimized/master",
+	}, got)
+}
+
+func Test_profileInstanceConfigurationColumns(t *testing.T) {
+	configurations := []profileInstanceConfiguration{
+		{
+			profile: "azure-storage-optimized",
+			tier:    "hot",
+			InstanceConfiguration: InstanceConfiguration{
+				ID:                "azure.es.datahot.lsv3",
+				InstanceType:      "elasticsearch",
+				StorageMultiplier: 35,
+				CPUMultiplier:     0.5,
+				DiscreteSizes:     DiscreteSizes{Sizes: []int{1024, 2048}, DefaultSize: 2048, Resource: "memory"},
+			},
+		},
+	}
+
+	w := &bytes.Buffer{}
+	err := renderColumns(w, "csv", profileInstanceConfigurationColumns, configurations)
+	require.NoError(t, err)
+
+	require.Equal(t, `profile,tier,instance_configuration,instance_type,storage_multiplier,cpu_multiplier,resource,sizes_mib,default_size_mib,max_node_disk_mib
+azure-storage-optimized,hot,azure.es.datahot.lsv3,elasticsearch,35,0.5,memory,"1024,2048",2048,71680
+`, w.String())
+
+	w.Reset()
+	err = renderColumns(w, "compact", profileInstanceConfigurationColumns, configurations)
+	require.NoError(t, err)
+	require.Contains(t, w.String(), "1GiB, 2GiB")
+	require.Contains(t, w.String(), "70GiB")
+}
+
+func Test_profileInstanceConfigurationColumns_json(t *testing.T) {
+	configurations := []profileInstanceConfiguration{
+		{
+			profile: "azure-storage-optimized",
+			tier:    "hot",
+			InstanceConfiguration: InstanceConfiguration{
+				ID:            "azure.es.datahot.lsv3",
+				DiscreteSizes: DiscreteSizes{Sizes: []int{1024, 2048}, DefaultSize: 2048, Resource: "memory"},
+			},
+		},
+	}
+
+	w := &bytes.Buffer{}
+	err := renderColumns(w, "ndjson", profileInstanceConfigurationColumns, configurations)
+	require.NoError(t, err)
+	require.Contains(t, w.String(), `"sizes_mib":[1024,2048]`)
+}
@@ -2,282 +2,526 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"time"
 
 	"github.com/urfave/cli/v3"
 )
 
 type region struct {
 	region string
-	name   string
+	// provider is the cloud provider of the region, e.g. aws. It can not be
+	// derived from the region, since not all region IDs start with the
+	// provider, e.g. us-east-1.
+	provider string
+	name     string
+	// available is nil, if the availability of the region is unknown, which
+	// is the case for the built-in regions.
+	available *bool
 }
 
-// Regions taken from https://www.elastic.co/docs/reference/cloud/cloud-hosted/regions
+// Built-in regions, used if the regions can not be fetched from the Elastic
+// Cloud API. Regions taken from https://www.elastic.co/docs/reference/cloud/cloud-hosted/regions
 var awsRegions = []region{
 	{
-		region: "aws-af-south-1",
-		name:   "Africa (Cape Town)",
+		region:   "aws-af-south-1",
+		provider: "aws",
+		name:     "Africa (Cape Town)",
 	},
 	{
-		region: "aws-ap-east-1",
-		name:   "Asia Pacific (Hong Kong)",
+		region:   "aws-ap-east-1",
+		provider: "aws",
+		name:     "Asia Pacific (Hong Kong)",
 	},
 	{
-		region: "ap-northeast-1",
-		name:   "Asia Pacific (Tokyo)",
+		region:   "ap-northeast-1",
+		provider: "aws",
+		name:     "Asia Pacific (Tokyo)",
 	},
 	{
-		region: "aws-ap-northeast-2",
-		name:   "Asia Pacific (Seoul)",
+		region:   "aws-ap-northeast-2",
+		provider: "aws",
+		name:     "Asia Pacific (Seoul)",
 	},
 	{
-		region: "aws-ap-south-1",
-		name:   "Asia Pacific (Mumbai)",
+		region:   "aws-ap-south-1",
+		provider: "aws",
+		name:     "Asia Pacific (Mumbai)",
 	},
 	{
-		region: "ap-southeast-1",
-		name:   "Asia Pacific (Singapore)",
+		region:   "ap-southeast-1",
+		provider: "aws",
+		name:     "Asia Pacific (Singapore)",
 	},
 	{
-		region: "ap-southeast-2",
-		name:   "Asia Pacific (Sydney)",
+		region:   "ap-southeast-2",
+		provider: "aws",
+		name:     "Asia Pacific (Sydney)",
 	},
 	{
-		region: "aws-ca-central-1",
-		name:   "Canada (central)",
+		region:   "aws-ca-central-1",
+		provider: "aws",
+		name:     "Canada (central)",
 	},
 	{
-		region: "aws-eu-central-1",
-		name:   "EU (Frankfurt)",
+		region:   "aws-eu-central-1",
+		provider: "aws",
+		name:     "EU (Frankfurt)",
 	},
 	{
-		region: "aws-eu-central-2",
-		name:   "EU (Zurich)",
+		region:   "aws-eu-central-2",
+		provider: "aws",
+		name:     "EU (Zurich)",
 	},
 	{
-		region: "aws-eu-north-1",
-		name:   "EU (Stockholm)",
+		region:   "aws-eu-north-1",
+		provider: "aws",
+		name:     "EU (Stockholm)",
 	},
 	{
-		region: "aws-eu-south-1",
-		name:   "EU (Milan)",
+		region:   "aws-eu-south-1",
+		provider: "aws",
+		name:     "EU (Milan)",
 	},
 	{
-		region: "eu-west-1",
-		name:   "EU (Ireland)",
+		region:   "eu-west-1",
+		provider: "aws",
+		name:     "EU (Ireland)",
 	},
 	{
-		region: "aws-eu-west-2",
-		name:   "EU (London)",
+		region:   "aws-eu-west-2",
+		provider: "aws",
+		name:     "EU (London)",
 	},
 	{
-		region: "aws-eu-west-3",
-		name:   "EU (Paris)",
+		region:   "aws-eu-west-3",
+		provider: "aws",
+		name:     "EU (Paris)",
 	},
 	{
-		region: "aws-me-south-1",
-		name:   "Middle East (Bahrain)",
+		region:   "aws-me-south-1",
+		provider: "aws",
+		name:     "Middle East (Bahrain)",
 	},
 	{
-		region: "sa-east-1",
-		name:   "South America (São Paulo)",
+		region:   "sa-east-1",
+		provider: "aws",
+		name:     "South America (São Paulo)",
 	},
 	{
-		region: "us-east-1",
-		name:   "US East (N. Virginia)",
+		region:   "us-east-1",
+		provider: "aws",
+		name:     "US East (N. Virginia)",
 	},
 	{
-		region: "aws-us-east-2",
-		name:   "US East (Ohio)",
+		region:   "aws-us-east-2",
+		provider: "aws",
+		name:     "US East (Ohio)",
 	},
 	{
-		region: "us-west-1",
-		name:   "US West (N. California)",
+		region:   "us-west-1",
+		provider: "aws",
+		name:     "US West (N. California)",
 	},
 	{
-		region: "us-west-2",
-		name:   "US West (Oregon)",
+		region:   "us-west-2",
+		provider: "aws",
+		name:     "US West (Oregon)",
 	},
 }
 
 var gcpRegions = []region{
 	{
-		region: "gcp-asia-east1",
-		name:   "Asia Pacific East 1 (Taiwan)",
+		region:   "gcp-asia-east1",
+		provider: "gcp",
+		name:     "Asia Pacific East 1 (Taiwan)",
 	},
 	{
-		region: "gcp-asia-northeast1",
-		name:   "Asia Pacific Northeast 1 (Tokyo)",
+		region:   "gcp-asia-northeast1",
+		provider: "gcp",
+		name:     "Asia Pacific Northeast 1 (Tokyo)",
 	},
 	{
-		region: "gcp-asia-northeast3",
-		name:   "Asia Pacific Northeast 3 (Seoul)",
+		region:   "gcp-asia-northeast3",
+		provider: "gcp",
+		name:     "Asia Pacific Northeast 3 (Seoul)",
 	},
 	{
-		region: "gcp-asia-south1",
-		name:   "Asia Pacific South 1 (Mumbai)",
+		region:   "gcp-asia-south1",
+		provider: "gcp",
+		name:     "Asia Pacific South 1 (Mumbai)",
 	},
 	{
-		region: "gcp-asia-southeast1",
-		name:   "Asia Pacific Southeast 1 (Singapore)",
+		region:   "gcp-asia-southeast1",
+		provider: "gcp",
+		name:     "Asia Pacific Southeast 1 (Singapore)",
 	},
 	{
-		region: "gcp-asia-southeast2",
-		name:   "Asia Pacific Southeast 2 (Jakarta)",
+		region:   "gcp-asia-southeast2",
+		provider: "gcp",
+		name:     "Asia Pacific Southeast 2 (Jakarta)",
 	},
 	{
-		region: "gcp-australia-southeast1",
-		name:   "Asia Pacific Southeast 1 (Sydney)",
+		region:   "gcp-australia-southeast1",
+		provider: "gcp",
+		name:     "Asia Pacific Southeast 1 (Sydney)",
 	},
 	{
-		region: "gcp-europe-north1",
-		name:   "Europe North 1 (Finland)",
+		region:   "gcp-europe-north1",
+		provider: "gcp",
+		name:     "Europe North 1 (Finland)",
 	},
 	{
-		region: "gcp-europe-west1",
-		name:   "Europe West 1 (Belgium)",
+		region:   "gcp-europe-west1",
+		provider: "gcp",
+		name:     "Europe West 1 (Belgium)",
 	},
 	{
-		region: "gcp-europe-west2",
-		name:   "Europe West 2 (London)",
+		region:   "gcp-europe-west2",
+		provider: "gcp",
+		name:     "Europe West 2 (London)",
 	},
 	{
-		region: "gcp-europe-west3",
-		name:   "Europe West 3 (Frankfurt)",
+		region:   "gcp-europe-west3",
+		provider: "gcp",
+		name:     "Europe West 3 (Frankfurt)",
 	},
 	{
-		region: "gcp-europe-west4",
-		name:   "Europe West 4 (Netherlands)",
+		region:   "gcp-europe-west4",
+		provider: "gcp",
+		name:     "Europe West 4 (Netherlands)",
 	},
 	{
-		region: "gcp-europe-west9",
-		name:   "Europe West 9 (Paris)",
+		region:   "gcp-europe-west9",
+		provider: "gcp",
+		name:     "Europe West 9 (Paris)",
 	},
 	{
-		region: "gcp-me-west1",
-		name:   "ME West 1 (Tel Aviv)",
+		region:   "gcp-me-west1",
+		provider: "gcp",
+		name:     "ME West 1 (Tel Aviv)",
 	},
 	{
-		region: "gcp-northamerica-northeast1",
-		name:   "North America Northeast 1 (Montreal)",
+		region:   "gcp-northamerica-northeast1",
+		provider: "gcp",
+		name:     "North America Northeast 1 (Montreal)",
 	},
 	{
-		region: "gcp-southamerica-east1",
-		name:   "South America East 1 (Sao Paulo)",
+		region:   "gcp-southamerica-east1",
+		provider: "gcp",
+		name:     "South America East 1 (Sao Paulo)",
 	},
 	{
-		region: "gcp-us-central1",
-		name:   "US Central 1 (Iowa)",
+		region:   "gcp-us-central1",
+		provider: "gcp",
+		name:     "US Central 1 (Iowa)",
 	},
 	{
-		region: "gcp-us-east1",
-		name:   "US East 1 (South Carolina)",
+		region:   "gcp-us-east1",
+		provider: "gcp",
+		name:     "US East 1 (South Carolina)",
 	},
 	{
-		region: "gcp-us-east4",
-		name:   "US East 4 (N. Virginia)",
+		region:   "gcp-us-east4",
+		provider: "gcp",
+		name:     "US East 4 (N. Virginia)",
 	},
 	{
-		region: "gcp-us-west1",
-		name:   "US West 1 (Oregon)",
+		region:   "gcp-us-west1",
+		provider: "gcp",
+		name:     "US West 1 (Oregon)",
 	},
 }
 
 var azureRegions = []region{
 	{
-		region: "azure-australiaeast",
-		name:   "Australia East (New South Wales)",
+		region:   "azure-australiaeast",
+		provider: "azure",
+		name:     "Australia East (New South Wales)",
 	},
 	{
-		region: "azure-brazilsouth",
-		name:   "Brazil South (São Paulo)",
+		region:   "azure-brazilsouth",
+		provider: "azure",
+		name:     "Brazil South (São Paulo)",
 	},
 	{
-		region: "azure-canadacentral",
-		name:   "Canada Central (Toronto)",
+		region:   "azure-canadacentral",
+		provider: "azure",
+		name:     "Canada Central (Toronto)",
 	},
 	{
-		region: "azure-centralindia",
-		name:   "Central India (Pune)",
+		region:   "azure-centralindia",
+		provider: "azure",
+		name:     "Central India (Pune)",
 	},
 	{
-		region: "azure-centralus",
-		name:   "Central US (Iowa)",
+		region:   "azure-centralus",
+		provider: "azure",
+		name:     "Central US (Iowa)",
 	},
 	{
-		region: "azure-eastus",
-		name:   "East US (Virginia)",
+		region:   "azure-eastus",
+		provider: "azure",
+		name:     "East US (Virginia)",
 	},
 	{
-		region: "azure-eastus2",
-		name:   "East US 2 (Virginia)",
+		region:   "azure-eastus2",
+		provider: "azure",
+		name:     "East US 2 (Virginia)",
 	},
 	{
-		region: "azure-francecentral",
-		name:   "France Central (Paris)",
+		region:   "azure-francecentral",
+		provider: "azure",
+		name:     "France Central (Paris)",
 	},
 	{
-		region: "azure-japaneast",
-		name:   "Japan East (Tokyo, Saitama)",
+		region:   "azure-japaneast",
+		provider: "azure",
+		name:     "Japan East (Tokyo, Saitama)",
 	},
 	{
-		region: "azure-northeurope",
-		name:   "North Europe (Ireland)",
+		region:   "azure-northeurope",
+		provider: "azure",
+		name:     "North Europe (Ireland)",
 	},
 	{
-		region: "azure-southafricanorth",
-		name:   "South Africa North (Johannesburg)",
+		region:   "azure-southafricanorth",
+		provider: "azure",
+		name:     "South Africa North (Johannesburg)",
 	},
 	{
-		region: "azure-southcentralus",
-		name:   "South Central US (Texas)",
+		region:   "azure-southcentralus",
+		provider: "azure",
+		name:     "South Central US (Texas)",
 	},
 	{
-		region: "azure-southeastasia",
-		name:   "South East Asia (Singapore)",
+		region:   "azure-southeastasia",
+		provider: "azure",
+		name:     "South East Asia (Singapore)",
 	},
 	{
-		region: "azure-uksouth",
-		name:   "UK South (London)",
+		region:   "azure-uksouth",
+		provider: "azure",
+		name:     "UK South (London)",
 	},
 	{
-		region: "azure-westeurope",
-		name:   "West Europe (Netherlands)",
+		region:   "azure-westeurope",
+		provider: "azure",
+		name:     "West Europe (Netherlands)",
 	},
 	{
-		region: "azure-westus2",
-		name:   "West US 2 (Washington)",
+		region:   "azure-westus2",
+		provider: "azure",
+		name:     "West US 2 (Washington)",
 	},
 }
 
 var allRegions = append(awsRegions, append(gcpRegions, azureRegions...)...)
 
-func listRegions(ctx context.Context, cmd *cli.Command) error {
-	fmt.Fprintf(cmd.Writer, "Regions:\n")
-	fmt.Fprintf(cmd.Writer, "  AWS:\n")
-	for _, r := range awsRegions {
-		fmt.Fprintf(cmd.Writer, "    %s - %s\n", r.region, r.name)
+// regionsURL is the Elastic Cloud API endpoint listing the regions.
+var regionsURL = "https://api.elastic-cloud.com/api/v1/regions"
+
+const (
+	regionsCacheName = "regions.json"
+	regionsCacheTTL  = 24 * time.Hour
+)
+
+// cloudRegion is a region as returned by the Elastic Cloud API.
+type cloudRegion struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Provider  string `json:"provider"`
+	Available bool   `json:"available"`
+}
+
+type cloudRegions struct {
+	Regions []cloudRegion `json:"regions"`
+}
+
+// fetchRegions fetches the regions from the Elastic Cloud API.
+func fetchRegions(ctx context.Context, url string) ([]cloudRegion, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch regions from %s: %s", url, resp.Status)
+	}
+
+	var regions cloudRegions
+	err = json.Unmarshal(body, &regions)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(regions.Regions) == 0 {
+		return nil, fmt.Errorf("no regions returned from %s", url)
+	}
+
+	return regions.Regions, nil
+}
+
+// regionsFromCloud converts the regions returned by the Elastic Cloud API and
+// sorts them by provider and region.
+func regionsFromCloud(cloud []cloudRegion) []region {
+	regions := make([]region, 0, len(cloud))
+	for _, c := range cloud {
+		available := c.Available
+		regions = append(regions, region{region: c.ID, provider: c.Provider, name: c.Name, available: &available})
+	}
+
+	sortRegions(regions)
+
+	return regions
+}
+
+func sortRegions(regions []region) {
+	sort.Slice(regions, func(i, j int) bool {
+		if regions[i].provider != regions[j].provider {
+			return regions[i].provider < regions[j].provider
+		}
+
+		return regions[i].region < regions[j].region
+	})
+}
+
+// getRegions returns the regions from the cache, if it is younger than
+// regionsCacheTTL and refresh is false. Otherwise the regions are fetched from
+// the Elastic Cloud API and the cache is updated. If fetching fails, the
+// expired cache is used and the built-in regions as last resort. Failures are
+// reported as warnings to w.
+func getRegions(ctx context.Context, w io.Writer, refresh bool) []region {
+	now := time.Now()
+
+	cached, cacheErr := readCache[[]cloudRegion](regionsCacheName)
+	if cacheErr != nil && !errors.Is(cacheErr, os.ErrNotExist) {
+		fmt.Fprintf(w, "WARNING: %v\n", cacheErr)
+	}
+
+	if cacheErr == nil && !refresh && !cached.expired(now, regionsCacheTTL) {
+		return regionsFromCloud(cached.Data)
 	}
 
-	fmt.Fprintf(cmd.Writer, "  GCP:\n")
-	for _, r := range gcpRegions {
-		fmt.Fprintf(cmd.Writer, "    %s - %s\n", r.region, r.name)
+	fetched, err := fetchRegions(ctx, regionsURL)
+	if err == nil {
+		err = writeCache(regionsCacheName, fetched, now)
+		if err != nil {
+			fmt.Fprintf(w, "WARNING: failed to cache regions: %v\n", err)
+		}
+
+		return regionsFromCloud(fetched)
 	}
 
-	fmt.Fprintf(cmd.Writer, "  Azure:\n")
-	for _, r := range azureRegions {
-		fmt.Fprintf(cmd.Writer, "    %s - %s\n", r.region, r.name)
+	if cacheErr == nil {
+		fmt.Fprintf(w, "WARNING: %v, using regions cached at %s\n", err, cached.Fetched.Format(time.RFC3339))
+		return regionsFromCloud(cached.Data)
 	}
 
-	return nil
+	fmt.Fprintf(w, "WARNING: %v, using built-in regions\n", err)
+
+	regions := append([]region{}, allRegions...)
+	sortRegions(regions)
+
+	return regions
 }
 
+var regionColumns = []column[region]{
+	{
+		header: "Provider", key: "provider",
+		value: func(r region) string { return r.provider },
+		raw:   func(r region) any { return r.provider },
+	},
+	{
+		header: "Region", key: "region",
+		value: func(r region) string { return r.region },
+		raw:   func(r region) any { return r.region },
+	},
+	{
+		header: "Name", key: "name",
+		value: func(r region) string { return r.name },
+		raw:   func(r region) any { return r.name },
+	},
+	{
+		header: "Available", key: "available",
+		value: func(r region) string {
+			switch {
+			case r.available == nil:
+				return "unknown"
+			case *r.available:
+				return "yes"
+			}
+
+			return "no"
+		},
+		raw: func(r region) any {
+			if r.available == nil {
+				return nil
+			}
+
+			return *r.available
+		},
+	},
+}
+
+func listRegions(ctx context.Context, cmd *cli.Command) error {
+	format := cmd.String("format")
+	err := validateOutputFormat(format)
+	if err != nil {
+		return err
+	}
+
+	regions := getRegions(ctx, cmd.ErrWriter, cmd.Bool("refresh"))
+
+	return renderColumns(cmd.Writer, format, regionColumns, regions)
+}
+
+// isRegionValid returns true, if region is one of the built-in regions or one
+// of the regions cached from the Elastic Cloud API. The cache is not refreshed
+// to avoid an API request for every command, regions added to Elastic Cloud
+// after the release of ec_check become valid after running ec_check regions.
 func isRegionValid(region string) bool {
+	_, ok := findRegion(region)
+	return ok
+}
+
+// findRegion returns the region with the given ID from the regions cached from
+// the Elastic Cloud API or the built-in regions. See isRegionValid.
+func findRegion(id string) (region, bool) {
+	cached, err := readCache[[]cloudRegion](regionsCacheName)
+	if err == nil {
+		for _, r := range regionsFromCloud(cached.Data) {
+			if r.region == id {
+				return r, true
+			}
+		}
+	}
+
 	for _, r := range allRegions {
-		if r.region == region {
-			return true
+		if r.region == id {
+			return r, true
 		}
 	}
 
-	return false
+	return region{}, false
 }
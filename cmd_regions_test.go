@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_builtinRegions(t *testing.T) {
+	providers := map[string][]region{
+		"aws":   awsRegions,
+		"gcp":   gcpRegions,
+		"azure": azureRegions,
+	}
+
+	for provider, regions := range providers {
+		for _, r := range regions {
+			require.Equal(t, provider, r.provider, r.region)
+			require.True(t, isRegionValid(r.region), r.region)
+		}
+	}
+}
+
+// withTestAPI points the API URL variable url to a test server responding
+// with the given status and body and uses an empty cache directory. The status
+// can be changed by the test, the returned counter contains the number of
+// requests.
+func withTestAPI(t *testing.T, url *string, status *int, body string) *int {
+	t.Helper()
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(*status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	previous := *url
+	*url = server.URL
+	t.Cleanup(func() { *url = previous })
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	return &requests
+}
+
+func Test_getRegions(t *testing.T) {
+	status := http.StatusOK
+	withTestAPI(t, &regionsURL, &status, `{"regions": [
+		{"id": "gcp-europe-west6", "name": "Europe West 6 (Zurich)", "provider": "gcp", "available": false},
+		{"id": "us-east-1", "name": "US East (N. Virginia)", "provider": "aws", "available": true},
+		{"id": "aws-eu-central-1", "name": "EU (Frankfurt)", "provider": "aws", "available": true}
+	]}`)
+
+	w := &bytes.Buffer{}
+	regions := getRegions(context.Background(), w, false)
+
+	available, unavailable := true, false
+	require.Equal(t, []region{
+		{region: "aws-eu-central-1", provider: "aws", name: "EU (Frankfurt)", available: &available},
+		{region: "us-east-1", provider: "aws", name: "US East (N. Virginia)", available: &available},
+		{region: "gcp-europe-west6", provider: "gcp", name: "Europe West 6 (Zurich)", available: &unavailable},
+	}, regions)
+	require.Empty(t, w.String())
+
+	// Regions from the cache become valid.
+	require.True(t, isRegionValid("gcp-europe-west6"))
+
+	cached, err := readCache[[]cloudRegion](regionsCacheName)
+	require.NoError(t, err)
+	require.Len(t, cached.Data, 3)
+
+	url, err := deploymentURL("test", "gcp-europe-west6")
+	require.NoError(t, err)
+	require.Equal(t, "https://test.es.europe-west6.gcp.elastic-cloud.com", url)
+
+	url, err = deploymentURL("test", "us-east-1")
+	require.NoError(t, err)
+	require.Equal(t, "https://test.es.us-east-1.aws.elastic-cloud.com", url)
+}
+
+func Test_getRegions_fallback(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	withTestAPI(t, &regionsURL, &status, "")
+
+	w := &bytes.Buffer{}
+	regions := getRegions(context.Background(), w, false)
+
+	require.Len(t, regions, len(allRegions))
+	require.Nil(t, regions[0].available)
+	require.Contains(t, w.String(), "using built-in regions")
+
+	// Expired cache is preferred over the built-in regions.
+	err := writeCache(regionsCacheName, []cloudRegion{{ID: "aws-eu-central-1", Name: "EU (Frankfurt)", Available: true}}, time.Now().Add(-2*regionsCacheTTL))
+	require.NoError(t, err)
+
+	w.Reset()
+	regions = getRegions(context.Background(), w, false)
+
+	require.Len(t, regions, 1)
+	require.Contains(t, w.String(), "using regions cached at")
+}
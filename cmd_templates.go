@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+)
+
+// templateSourceFlags returns the flags to select the source of the
+// deployment templates, see templateSource.
+func templateSourceFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "offline",
+			Usage: "Do not fetch the deployment templates from the Elastic Cloud API, use the cached ones (see ec_check templates sync) regardless of their age",
+			Local: true,
+		},
+		&cli.StringSliceFlag{
+			Name:  "templates-file",
+			Usage: "Read the deployment templates from the given JSON files as returned by the Elastic Cloud API instead of the API or the cache",
+			Local: true,
+		},
+		&cli.DurationFlag{
+			Name:  "cache-ttl",
+			Usage: "Use the cached deployment templates without fetching them again from the Elastic Cloud API, if they are younger than the given duration",
+			Value: deploymentTemplatesCacheTTL,
+			Local: true,
+		},
+	}
+}
+
+// templateSourceFromCommand returns the templateSource given by the flags of
+// templateSourceFlags.
+func templateSourceFromCommand(cmd *cli.Command) templateSource {
+	return templateSource{
+		offline:  cmd.Bool("offline"),
+		files:    cmd.StringSlice("templates-file"),
+		cacheTTL: cmd.Duration("cache-ttl"),
+	}
+}
+
+func templatesSync(ctx context.Context, cmd *cli.Command) error {
+	regions := []string{cmd.String("region")}
+	if cmd.Bool("all-regions") {
+		regions = regions[:0]
+		for _, r := range getRegions(ctx, cmd.ErrWriter, false) {
+			regions = append(regions, r.region)
+		}
+	}
+
+	var failed int
+	for _, region := range regions {
+		if !isRegionValid(region) {
+			return fmt.Errorf("region %q is not a known Elastic Cloud region", region)
+		}
+
+		deploymentTemplates, err := syncDeploymentTemplates(ctx, region)
+		if err != nil {
+			fmt.Fprintf(cmd.Writer, "%s: %v\n", region, err)
+			failed++
+			continue
+		}
+
+		fmt.Fprintf(cmd.Writer, "%s: %d deployment templates cached\n", region, len(deploymentTemplates))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to sync deployment templates for %d of %d regions", failed, len(regions))
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/expandwildcard"
+)
+
+// backingIndex contains the data stream a backing index belongs to.
+type backingIndex struct {
+	dataStream string
+	// writeIndex is true, if the index is the current write index of the data
+	// stream.
+	writeIndex bool
+}
+
+// getBackingIndices returns the backing indices of all data streams of the
+// cluster by index name.
+func getBackingIndices(ctx context.Context, client *elasticsearch.TypedClient) (map[string]backingIndex, error) {
+	res, err := client.Indices.GetDataStream().ExpandWildcards(expandwildcard.All).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return backingIndices(res.DataStreams), nil
+}
+
+// backingIndices returns the backing indices of the data streams by index
+// name. The indices of a data stream are ordered by generation, the last one
+// is the write index.
+func backingIndices(dataStreams []types.DataStream) map[string]backingIndex {
+	indices := make(map[string]backingIndex)
+	for _, dataStream := range dataStreams {
+		for i, index := range dataStream.Indices {
+			indices[index.IndexName] = backingIndex{
+				dataStream: dataStream.Name,
+				writeIndex: i == len(dataStream.Indices)-1,
+			}
+		}
+	}
+
+	return indices
+}
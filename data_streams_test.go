@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_backingIndices(t *testing.T) {
+	dataStreams := []types.DataStream{
+		{
+			Name: "logs-app-default",
+			Indices: []types.DataStreamIndex{
+				{IndexName: ".ds-logs-app-default-2025.01.01-000001"},
+				{IndexName: ".ds-logs-app-default-2025.01.02-000002"},
+			},
+		},
+		{
+			Name: "metrics",
+			Indices: []types.DataStreamIndex{
+				{IndexName: ".ds-metrics-2025.01.01-000001"},
+			},
+		},
+	}
+
+	want := map[string]backingIndex{
+		".ds-logs-app-default-2025.01.01-000001": {dataStream: "logs-app-default"},
+		".ds-logs-app-default-2025.01.02-000002": {dataStream: "logs-app-default", writeIndex: true},
+		".ds-metrics-2025.01.01-000001":          {dataStream: "metrics", writeIndex: true},
+	}
+
+	require.Equal(t, want, backingIndices(dataStreams))
+}
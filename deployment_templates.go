@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/docker/go-units"
 )
@@ -85,7 +90,7 @@ type InstanceConfiguration struct {
 type DiscreteSizes struct {
 	Sizes       []int  `json:"sizes"`
 	DefaultSize int    `json:"default_size"`
-	Resource    string `json:"resouce"`
+	Resource    string `json:"resource"`
 }
 
 const maxNodesPerTier = 32
@@ -94,36 +99,14 @@ const mibMultiplier = 1024 * 1024
 
 var tierRegexp = regexp.MustCompile(`\.es\.data([^\.]+)\.`)
 
-// getTierSizes extracts the instance configurations from the deployment templates
-// provided by Elastic Cloud and returns the available sizing configurations
-// (memory and disk) for each Tier.
-func getTierSizes(region string, profile string) (TierSizes, error) {
-	deploymentTemplateURL := fmt.Sprintf("https://api.elastic-cloud.com/api/v1/deployments/templates/%s?region=%s", profile, region)
-
-	resp, err := http.Get(deploymentTemplateURL)
-	if err != nil {
-		return nil, err
-	}
-
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var deploymentTemplate DeploymentTemplate
-	err = json.Unmarshal(body, &deploymentTemplate)
-	if err != nil {
-		return nil, err
-	}
-
+// tierSizesFromTemplate extracts the instance configurations from the
+// deployment template provided by Elastic Cloud and returns the available
+// sizing configurations (memory and disk) for each Tier.
+func tierSizesFromTemplate(deploymentTemplate DeploymentTemplate) TierSizes {
 	tierSizes := make(TierSizes, 4)
 	for _, template := range deploymentTemplate.InstanceConfigurations {
 		tierMatches := tierRegexp.FindStringSubmatch(template.ID)
-		if len(tierMatches) != 2 {
+		if len(tierMatches) != 2 || len(template.DiscreteSizes.Sizes) == 0 {
 			continue
 		}
 
@@ -141,13 +124,14 @@ func getTierSizes(region string, profile string) (TierSizes, error) {
 			)
 		}
 
-		fullNodeDiskSize := float64(template.DiscreteSizes.Sizes[len(template.DiscreteSizes.Sizes)-1]) * template.StorageMultiplier * mibMultiplier
+		fullNodeMemorySize := float64(template.DiscreteSizes.Sizes[len(template.DiscreteSizes.Sizes)-1]) * mibMultiplier
+		fullNodeDiskSize := fullNodeMemorySize * template.StorageMultiplier
 
 		// Full nodes, adding adding 64 MB of memory each to the cluster.
 		for size := 2.0; size <= maxNodesPerTier; size++ {
 			sizes = append(sizes,
 				Size{
-					Memory: size,
+					Memory: size * fullNodeMemorySize,
 					Disk:   size * fullNodeDiskSize,
 				},
 			)
@@ -156,5 +140,188 @@ func getTierSizes(region string, profile string) (TierSizes, error) {
 		tierSizes[tier] = sizes
 	}
 
-	return tierSizes, nil
+	return tierSizes
+}
+
+// deploymentTemplatesURL is the Elastic Cloud API endpoint listing the
+// deployment templates of a region.
+var deploymentTemplatesURL = "https://api.elastic-cloud.com/api/v1/deployments/templates"
+
+// deploymentTemplatesCacheTTL is the default time the cached deployment
+// templates are used without fetching them again, see --cache-ttl.
+const deploymentTemplatesCacheTTL = 24 * time.Hour
+
+// deploymentTemplatesCacheName returns the name of the cache file for the
+// deployment templates of the region.
+func deploymentTemplatesCacheName(region string) string {
+	return fmt.Sprintf("templates-%s.json", region)
+}
+
+// templateSource controls where loadDeploymentTemplates takes the deployment
+// templates from.
+type templateSource struct {
+	// offline disables fetching the deployment templates from the Elastic
+	// Cloud API, only the cache or the files are used.
+	offline bool
+	// files are JSON files with deployment templates as returned by the
+	// Elastic Cloud API, which are used instead of the API and the cache.
+	files []string
+	// cacheTTL is the time the cached deployment templates are used without
+	// fetching them again, deploymentTemplatesCacheTTL if zero.
+	cacheTTL time.Duration
+}
+
+// ttl returns the time the cached deployment templates are used without
+// fetching them again.
+func (s templateSource) ttl() time.Duration {
+	if s.cacheTTL > 0 {
+		return s.cacheTTL
+	}
+
+	return deploymentTemplatesCacheTTL
+}
+
+// fetchDeploymentTemplates fetches the deployment templates (profiles) of the
+// region including their instance configurations from the Elastic Cloud API.
+func fetchDeploymentTemplates(ctx context.Context, region string) (DeploymentTemplates, error) {
+	url := fmt.Sprintf("%s?region=%s&show_instance_configurations=true", deploymentTemplatesURL, region)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch deployment templates for region %q: %s", region, resp.Status)
+	}
+
+	var deploymentTemplates DeploymentTemplates
+	err = json.Unmarshal(body, &deploymentTemplates)
+	if err != nil {
+		return nil, err
+	}
+
+	return deploymentTemplates, nil
+}
+
+// readDeploymentTemplatesFiles reads the deployment templates from the JSON
+// files. Each file contains either a list of deployment templates or a single
+// deployment template as returned by the Elastic Cloud API.
+func readDeploymentTemplatesFiles(files []string) (DeploymentTemplates, error) {
+	var deploymentTemplates DeploymentTemplates
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+			var templates DeploymentTemplates
+			err = json.Unmarshal(data, &templates)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse deployment templates file %q: %w", file, err)
+			}
+
+			deploymentTemplates = append(deploymentTemplates, templates...)
+			continue
+		}
+
+		var template DeploymentTemplate
+		err = json.Unmarshal(data, &template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse deployment templates file %q: %w", file, err)
+		}
+
+		deploymentTemplates = append(deploymentTemplates, template)
+	}
+
+	return deploymentTemplates, nil
+}
+
+// loadDeploymentTemplates returns the deployment templates of the region
+// sorted by ID. If files are given, the deployment templates are read from
+// the files. Otherwise the cache is used, if it is younger than the cache TTL
+// of the source. If the cache is expired, the deployment
+// templates are fetched from the Elastic Cloud API and the cache is updated,
+// if fetching fails, the expired cache is used with a warning written to w.
+// In offline mode, the cache is used regardless of its age.
+func loadDeploymentTemplates(ctx context.Context, w io.Writer, region string, source templateSource) (DeploymentTemplates, error) {
+	deploymentTemplates, err := loadUnsortedDeploymentTemplates(ctx, w, region, source)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(deploymentTemplates, func(i, j int) bool {
+		return deploymentTemplates[i].ID < deploymentTemplates[j].ID
+	})
+
+	return deploymentTemplates, nil
+}
+
+func loadUnsortedDeploymentTemplates(ctx context.Context, w io.Writer, region string, source templateSource) (DeploymentTemplates, error) {
+	if len(source.files) > 0 {
+		return readDeploymentTemplatesFiles(source.files)
+	}
+
+	cacheName := deploymentTemplatesCacheName(region)
+
+	cached, cacheErr := readCache[DeploymentTemplates](cacheName)
+	if cacheErr != nil && !errors.Is(cacheErr, os.ErrNotExist) {
+		fmt.Fprintf(w, "WARNING: %v\n", cacheErr)
+	}
+
+	if cacheErr == nil && (source.offline || !cached.expired(time.Now(), source.ttl())) {
+		return cached.Data, nil
+	}
+
+	if source.offline {
+		return nil, fmt.Errorf("no cached deployment templates for region %q in offline mode, run ec_check templates sync --region %s or use --templates-file", region, region)
+	}
+
+	deploymentTemplates, err := fetchDeploymentTemplates(ctx, region)
+	if err == nil {
+		err = writeCache(cacheName, deploymentTemplates, time.Now())
+		if err != nil {
+			fmt.Fprintf(w, "WARNING: failed to cache deployment templates: %v\n", err)
+		}
+
+		return deploymentTemplates, nil
+	}
+
+	if cacheErr == nil {
+		fmt.Fprintf(w, "WARNING: %v, using deployment templates cached at %s\n", err, cached.Fetched.Format(time.RFC3339))
+		return cached.Data, nil
+	}
+
+	return nil, err
+}
+
+// syncDeploymentTemplates fetches the deployment templates of the region from
+// the Elastic Cloud API and updates the cache.
+func syncDeploymentTemplates(ctx context.Context, region string) (DeploymentTemplates, error) {
+	deploymentTemplates, err := fetchDeploymentTemplates(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeCache(deploymentTemplatesCacheName(region), deploymentTemplates, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to cache deployment templates for region %q: %w", region, err)
+	}
+
+	return deploymentTemplates, nil
 }
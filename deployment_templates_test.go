@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func templateIDs(deploymentTemplates DeploymentTemplates) []string {
+	ids := make([]string, 0, len(deploymentTemplates))
+	for _, dt := range deploymentTemplates {
+		ids = append(ids, dt.ID)
+	}
+
+	return ids
+}
+
+func Test_loadDeploymentTemplates(t *testing.T) {
+	status := http.StatusOK
+	requests := withTestAPI(t, &deploymentTemplatesURL, &status, `[{"id": "azure-storage-optimized"}, {"id": "azure-general-purpose"}]`)
+
+	ctx := context.Background()
+	w := &bytes.Buffer{}
+
+	// Offline without cache fails.
+	_, err := loadDeploymentTemplates(ctx, w, "azure-westeurope", templateSource{offline: true})
+	require.Error(t, err)
+	require.Equal(t, 0, *requests)
+
+	// Fetched from the API and cached.
+	deploymentTemplates, err := loadDeploymentTemplates(ctx, w, "azure-westeurope", templateSource{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"azure-general-purpose", "azure-storage-optimized"}, templateIDs(deploymentTemplates))
+	require.Equal(t, 1, *requests)
+
+	// Served from the cache.
+	_, err = loadDeploymentTemplates(ctx, w, "azure-westeurope", templateSource{})
+	require.NoError(t, err)
+	require.Equal(t, 1, *requests)
+
+	// Fetched again, if the cache is older than the cache TTL.
+	time.Sleep(time.Millisecond)
+	_, err = loadDeploymentTemplates(ctx, w, "azure-westeurope", templateSource{cacheTTL: time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, 2, *requests)
+
+	// Expired cache is used in offline mode and if the API fails.
+	err = writeCache(deploymentTemplatesCacheName("azure-westeurope"), DeploymentTemplates{{ID: "azure-cpu-optimized"}}, time.Now().Add(-2*deploymentTemplatesCacheTTL))
+	require.NoError(t, err)
+
+	deploymentTemplates, err = loadDeploymentTemplates(ctx, w, "azure-westeurope", templateSource{offline: true})
+	require.NoError(t, err)
+	require.Equal(t, []string{"azure-cpu-optimized"}, templateIDs(deploymentTemplates))
+	require.Equal(t, 2, *requests)
+	require.Empty(t, w.String())
+
+	status = http.StatusServiceUnavailable
+	deploymentTemplates, err = loadDeploymentTemplates(ctx, w, "azure-westeurope", templateSource{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"azure-cpu-optimized"}, templateIDs(deploymentTemplates))
+	require.Equal(t, 3, *requests)
+	require.Contains(t, w.String(), "using deployment templates cached at")
+
+	// Without cache, the error of the API is returned.
+	_, err = loadDeploymentTemplates(ctx, w, "azure-northeurope", templateSource{})
+	require.Error(t, err)
+}
+
+func Test_loadDeploymentTemplates_files(t *testing.T) {
+	status := http.StatusOK
+	requests := withTestAPI(t, &deploymentTemplatesURL, &status, `[]`)
+
+	dir := t.TempDir()
+	list := filepath.Join(dir, "list.json")
+	single := filepath.Join(dir, "single.json")
+	require.NoError(t, os.WriteFile(list, []byte(`[{"id": "azure-storage-optimized"}, {"id": "azure-general-purpose"}]`), 0o644))
+	require.NoError(t, os.WriteFile(single, []byte(` {"id": "azure-cpu-optimized", "instance_configurations": [{"id": "azure.es.datahot.fsv2", "storage_multiplier": 10, "discrete_sizes": {"sizes": [1024], "resource": "memory"}}]}`), 0o644))
+
+	deploymentTemplates, err := loadDeploymentTemplates(context.Background(), &bytes.Buffer{}, "azure-westeurope", templateSource{offline: true, files: []string{list, single}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"azure-cpu-optimized", "azure-general-purpose", "azure-storage-optimized"}, templateIDs(deploymentTemplates))
+	require.Equal(t, "memory", deploymentTemplates[0].InstanceConfigurations[0].DiscreteSizes.Resource)
+	require.Equal(t, 0, *requests)
+
+	_, err = loadDeploymentTemplates(context.Background(), &bytes.Buffer{}, "azure-westeurope", templateSource{files: []string{filepath.Join(dir, "missing.json")}})
+	require.Error(t, err)
+}
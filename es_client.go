@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/urfave/cli/v3"
+)
+
+// newTypedClient returns a typed Elasticsearch client for the deployment
+// given by the global flags deployment, region, username and password.
+func newTypedClient(cmd *cli.Command) (*elasticsearch.TypedClient, error) {
+	return newDeploymentClient(cmd.String("deployment"), cmd.String("region"), cmd.String("username"), cmd.String("password"))
+}
+
+// newDeploymentClient returns a typed Elasticsearch client for the given
+// Elastic Cloud deployment.
+func newDeploymentClient(deployment, region, username, password string) (*elasticsearch.TypedClient, error) {
+	url, err := deploymentURL(deployment, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return elasticsearch.NewTypedClient(elasticsearch.Config{
+		Addresses: []string{
+			url,
+		},
+		Username: username,
+		Password: password,
+	})
+}
+
+// deploymentURL returns the URL of the Elasticsearch endpoint of the given
+// Elastic Cloud deployment.
+func deploymentURL(deployment, regionID string) (string, error) {
+	r, ok := findRegion(regionID)
+	if !ok {
+		return "", fmt.Errorf("region %q is not a known Elastic Cloud region", regionID)
+	}
+
+	// The host contains the region without the provider prefix, e.g.
+	// westeurope.azure for azure-westeurope and us-east-1.aws for us-east-1.
+	providerRegion := strings.TrimPrefix(r.region, r.provider+"-")
+
+	return fmt.Sprintf("https://%s.es.%s.%s.elastic-cloud.com", deployment, providerRegion, r.provider), nil
+}
+
+// deploymentURLWithCredentials returns the URL of the Elasticsearch endpoint
+// of the given Elastic Cloud deployment including the credentials, if given.
+func deploymentURLWithCredentials(deployment, region, username, password string) (string, error) {
+	baseURL, err := deploymentURL(deployment, region)
+	if err != nil {
+		return "", err
+	}
+
+	if username == "" || password == "" {
+		return baseURL, nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	u.User = url.UserPassword(username, password)
+
+	return u.String(), nil
+}
@@ -26,3 +26,13 @@ func mapOrderedByKey[K cmp.Ordered, E any](m map[K]E) iter.Seq2[K, E] {
 		}
 	}
 }
+
+// deref returns the value p points to or the zero value of T, if p is nil.
+func deref[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+
+	return *p
+}
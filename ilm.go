@@ -0,0 +1,93 @@
+package main
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// ilmPhases contains all ILM phases in the order they are passed by an index.
+var ilmPhases = []string{"hot", "warm", "cold", "frozen", "delete"}
+
+// policyPhase returns the definition of the given phase from an ILM policy.
+// If the phase is not defined in the policy, nil is returned.
+func policyPhase(policy types.IlmPolicy, phase string) *types.Phase {
+	switch phase {
+	case "hot":
+		return policy.Phases.Hot
+	case "warm":
+		return policy.Phases.Warm
+	case "cold":
+		return policy.Phases.Cold
+	case "frozen":
+		return policy.Phases.Frozen
+	case "delete":
+		return policy.Phases.Delete
+	}
+
+	return nil
+}
+
+// phaseMinAge returns the min_age of a policy phase. A phase without min_age
+// is entered immediately, therefore 0 is returned in this case.
+func phaseMinAge(phase *types.Phase) (time.Duration, error) {
+	if phase == nil || phase.MinAge == nil {
+		return 0, nil
+	}
+
+	return parseESDuration(phase.MinAge)
+}
+
+// sortedPhases returns the keys of a map keyed by phase names in the order
+// the phases are passed by an index.
+func sortedPhases[E any](m map[string]E) []string {
+	phases := make([]string, 0, len(m))
+	for phase := range m {
+		phases = append(phases, phase)
+	}
+
+	sort.Slice(phases, func(i, j int) bool {
+		return phaseLess(phases[i], phases[j])
+	})
+
+	return phases
+}
+
+// phaseActions returns the names of the actions defined in a policy phase
+// ordered by name.
+func phaseActions(phase *types.Phase) ([]string, error) {
+	if phase == nil || phase.Actions == nil {
+		return nil, nil
+	}
+
+	actions, err := toJSONMap(phase.Actions)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(actions))
+	for name := range mapOrderedByKey(actions) {
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// nextPhase returns the name and the min_age of the first phase defined in the
+// policy, which follows the given phase. If there is no such phase, the empty
+// string is returned.
+func nextPhase(policy types.IlmPolicy, phase string) (string, time.Duration, error) {
+	for _, next := range ilmPhases[slices.Index(ilmPhases, phase)+1:] {
+		definition := policyPhase(policy, next)
+		if definition == nil {
+			continue
+		}
+
+		minAge, err := phaseMinAge(definition)
+		return next, minAge, err
+	}
+
+	return "", 0, nil
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/catnodecolumn"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/catshardcolumn"
+)
+
+// Additional information about the indices, which is not collected by
+// getIndexDetails, since it requires additional requests.
+const (
+	extraTiers     = "tiers"
+	extraRollover  = "rollover"
+	extraNextPhase = "next-phase"
+)
+
+// indexColumn is a column of the indices shown by ilm list.
+type indexColumn struct {
+	column[indexDetails]
+
+	// name is used to select the column with --columns and --sort.
+	name string
+	// sortKey returns the value the indices are sorted by. It is nil for
+	// columns, which can not be sorted.
+	sortKey func(indexDetails) int64
+	// extra is the additional information required by the column, see
+	// addIndexDetailsExtras.
+	extra string
+}
+
+// defaultIndexColumns are the columns shown by ilm list, if no columns are
+// given.
+var defaultIndexColumns = []string{"index", "data-stream", "write-index", "phase", "action", "step", "policy", "age", "pri-size", "total-size"}
+
+// indexColumns are all the columns of the indices shown by ilm list. Sizes are
+// given in bytes, durations in seconds and dates in RFC 3339 format in the
+// machine readable formats.
+var indexColumns = []indexColumn{
+	stringIndexColumn("index", "Index", func(i indexDetails) string { return i.name }),
+	stringIndexColumn("data-stream", "Data Stream", func(i indexDetails) string { return i.dataStream }),
+	{
+		name: "write-index",
+		column: column[indexDetails]{
+			header: "Write Index", key: "write_index",
+			value: func(i indexDetails) string {
+				if i.writeIndex {
+					return "yes"
+				}
+
+				return ""
+			},
+			raw: func(i indexDetails) any { return i.writeIndex },
+		},
+	},
+	stringIndexColumn("phase", "Phase", func(i indexDetails) string { return i.phase }),
+	stringIndexColumn("action", "Action", func(i indexDetails) string { return i.action }),
+	stringIndexColumn("step", "Step", func(i indexDetails) string { return i.step }),
+	stringIndexColumn("policy", "Policy", func(i indexDetails) string { return i.policy }),
+	durationIndexColumn("age", "Age", func(i indexDetails) time.Duration { return i.age }),
+	sizeIndexColumn("pri-size", "Pri Size", func(i indexDetails) int64 { return i.priSize }),
+	sizeIndexColumn("total-size", "Total Size", func(i indexDetails) int64 { return i.totalSize }),
+	countIndexColumn("docs", "Docs", func(i indexDetails) int64 { return i.docs }),
+	countIndexColumn("pri-shards", "Pri Shards", func(i indexDetails) int64 { return i.priShards }),
+	countIndexColumn("replica-shards", "Replica Shards", func(i indexDetails) int64 { return i.replicaShards }),
+	sizeIndexColumn("avg-shard-size", "Avg Shard Size", func(i indexDetails) int64 {
+		shards := i.priShards + i.replicaShards
+		if shards == 0 {
+			return 0
+		}
+
+		return i.totalSize / shards
+	}),
+	{
+		name:  "tier",
+		extra: extraTiers,
+		column: column[indexDetails]{
+			header: "Tier", key: "tier",
+			value: func(i indexDetails) string { return strings.Join(i.tiers, ",") },
+			raw:   func(i indexDetails) any { return strings.Join(i.tiers, ",") },
+		},
+	},
+	dateIndexColumn("lifecycle-date", "Lifecycle Date", func(i indexDetails) time.Time { return i.lifecycleDate }),
+	durationIndexColumn("phase-time", "Time in Phase", func(i indexDetails) time.Duration { return i.timeInPhase }),
+	withExtra(dateIndexColumn("rollover-date", "Rollover Date", func(i indexDetails) time.Time { return i.rolloverDate }), extraRollover),
+	withExtra(stringIndexColumn("next-phase", "Next Phase", func(i indexDetails) string { return i.nextPhase }), extraNextPhase),
+	withExtra(durationIndexColumn("next-phase-min-age", "Next Phase Min Age", func(i indexDetails) time.Duration { return i.nextPhaseMinAge }), extraNextPhase),
+}
+
+func stringIndexColumn(name string, header string, get func(indexDetails) string) indexColumn {
+	return indexColumn{
+		name: name,
+		column: column[indexDetails]{
+			header: header, key: strings.ReplaceAll(name, "-", "_"),
+			value: get,
+			raw:   func(i indexDetails) any { return get(i) },
+		},
+	}
+}
+
+func durationIndexColumn(name string, header string, get func(indexDetails) time.Duration) indexColumn {
+	return indexColumn{
+		name: name,
+		column: column[indexDetails]{
+			header: header, key: strings.ReplaceAll(name, "-", "_") + "_seconds",
+			value: func(i indexDetails) string { return formatDuration(get(i)) },
+			raw:   func(i indexDetails) any { return int64(get(i).Seconds()) },
+		},
+		sortKey: func(i indexDetails) int64 { return int64(get(i)) },
+	}
+}
+
+func sizeIndexColumn(name string, header string, get func(indexDetails) int64) indexColumn {
+	return indexColumn{
+		name: name,
+		column: column[indexDetails]{
+			header: header, key: strings.ReplaceAll(name, "-", "_") + "_bytes",
+			value: func(i indexDetails) string { return units.BytesSize(float64(get(i))) },
+			raw:   func(i indexDetails) any { return get(i) },
+		},
+		sortKey: get,
+	}
+}
+
+func countIndexColumn(name string, header string, get func(indexDetails) int64) indexColumn {
+	return indexColumn{
+		name: name,
+		column: column[indexDetails]{
+			header: header, key: strings.ReplaceAll(name, "-", "_"),
+			value: func(i indexDetails) string { return strconv.FormatInt(get(i), 10) },
+			raw:   func(i indexDetails) any { return get(i) },
+		},
+		sortKey: get,
+	}
+}
+
+func dateIndexColumn(name string, header string, get func(indexDetails) time.Time) indexColumn {
+	return indexColumn{
+		name: name,
+		column: column[indexDetails]{
+			header: header, key: strings.ReplaceAll(name, "-", "_"),
+			value: func(i indexDetails) string {
+				if get(i).IsZero() {
+					return ""
+				}
+
+				return get(i).UTC().Format(time.DateTime)
+			},
+			raw: func(i indexDetails) any {
+				if get(i).IsZero() {
+					return nil
+				}
+
+				return get(i).UTC().Format(time.RFC3339)
+			},
+		},
+		sortKey: func(i indexDetails) int64 { return get(i).UnixMilli() },
+	}
+}
+
+func withExtra(col indexColumn, extra string) indexColumn {
+	col.extra = extra
+	return col
+}
+
+// indexColumnNames returns the names of all index columns.
+func indexColumnNames(sortable bool) []string {
+	names := make([]string, 0, len(indexColumns))
+	for _, col := range indexColumns {
+		if sortable && col.sortKey == nil {
+			continue
+		}
+
+		names = append(names, col.name)
+	}
+
+	return names
+}
+
+// findIndexColumn returns the index column with the given name.
+func findIndexColumn(name string) (indexColumn, bool) {
+	i := slices.IndexFunc(indexColumns, func(col indexColumn) bool { return col.name == name })
+	if i < 0 {
+		return indexColumn{}, false
+	}
+
+	return indexColumns[i], true
+}
+
+// selectIndexColumns returns the index columns with the given names. If no
+// names are given, the defaultIndexColumns are returned.
+func selectIndexColumns(names []string) ([]indexColumn, error) {
+	if len(names) == 0 {
+		names = defaultIndexColumns
+	}
+
+	columns := make([]indexColumn, 0, len(names))
+	for _, name := range names {
+		col, ok := findIndexColumn(name)
+		if !ok {
+			return nil, fmt.Errorf("column %q is not supported, use one of %v", name, indexColumnNames(false))
+		}
+
+		columns = append(columns, col)
+	}
+
+	return columns, nil
+}
+
+// indexSort is a sort criteria for the indices.
+type indexSort struct {
+	column    indexColumn
+	ascending bool
+}
+
+// parseIndexSorts parses the sort criteria in the format <column>[:asc|:desc].
+// Without modifier, the indices are sorted in descending order.
+func parseIndexSorts(sorts []string) ([]indexSort, error) {
+	indexSorts := make([]indexSort, 0, len(sorts))
+	for _, sortStr := range sorts {
+		name, order, _ := strings.Cut(sortStr, ":")
+
+		col, ok := findIndexColumn(name)
+		if !ok || col.sortKey == nil {
+			return nil, fmt.Errorf("column %q is not allowed for sorting, use one of %v", name, indexColumnNames(true))
+		}
+
+		var ascending bool
+		switch order {
+		case "", "desc":
+		case "asc":
+			ascending = true
+		default:
+			return nil, fmt.Errorf("invalid sort order %q for column %q, use asc or desc", order, name)
+		}
+
+		indexSorts = append(indexSorts, indexSort{column: col, ascending: ascending})
+	}
+
+	return indexSorts, nil
+}
+
+// renderIndexColumns writes the given columns of the indices in the given
+// format to w.
+func renderIndexColumns(w io.Writer, format string, columns []indexColumn, indices []indexDetails) error {
+	cols := make([]column[indexDetails], 0, len(columns))
+	for _, col := range columns {
+		cols = append(cols, col.column)
+	}
+
+	return renderColumns(w, format, cols, indices)
+}
+
+// addIndexDetailsExtras fills the additional information of the indices
+// required by the given columns.
+func addIndexDetailsExtras(ctx context.Context, client *elasticsearch.TypedClient, indices []indexDetails, columns []indexColumn) error {
+	extras := make(map[string]bool, 3)
+	for _, col := range columns {
+		if col.extra != "" {
+			extras[col.extra] = true
+		}
+	}
+
+	if extras[extraTiers] {
+		tiers, err := getIndexTiers(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		for i := range indices {
+			indices[i].tiers = tiers[indices[i].name]
+		}
+	}
+
+	if extras[extraRollover] {
+		rolloverDates, err := getRolloverDates(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		for i := range indices {
+			indices[i].rolloverDate = rolloverDates[indices[i].name]
+		}
+	}
+
+	if extras[extraNextPhase] {
+		policies, err := client.Ilm.GetLifecycle().Do(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := range indices {
+			policy, ok := policies[indices[i].policy]
+			if !ok {
+				continue
+			}
+
+			indices[i].nextPhase, indices[i].nextPhaseMinAge, err = nextPhase(policy.Policy, indices[i].phase)
+			if err != nil {
+				return fmt.Errorf("failed to get next phase of policy %q: %w", indices[i].policy, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// getIndexTiers returns the data tiers of the nodes the shards of the indices
+// are currently allocated to.
+func getIndexTiers(ctx context.Context, client *elasticsearch.TypedClient) (map[string][]string, error) {
+	nodes, err := client.Cat.Nodes().H(catnodecolumn.Name, catnodecolumn.Noderole).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeTiers := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		if !strings.ContainsAny(deref(node.NodeRole), "hwcf") {
+			continue
+		}
+
+		nodeTiers[deref(node.Name)] = string(tierFromNodeRole(deref(node.NodeRole)))
+	}
+
+	shards, err := client.Cat.Shards().H(catshardcolumn.Index, catshardcolumn.Node).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	indexTiers := make(map[string][]string)
+	for _, shard := range shards {
+		tier, ok := nodeTiers[deref(shard.Node)]
+		if !ok {
+			continue
+		}
+
+		index := deref(shard.Index)
+		if !slices.Contains(indexTiers[index], tier) {
+			indexTiers[index] = append(indexTiers[index], tier)
+		}
+	}
+
+	for _, tiers := range indexTiers {
+		sort.Slice(tiers, func(i, j int) bool {
+			return phaseLess(tiers[i], tiers[j])
+		})
+	}
+
+	return indexTiers, nil
+}
+
+// getRolloverDates returns the date an index has been rolled over from the
+// rollover info in the cluster state.
+func getRolloverDates(ctx context.Context, client *elasticsearch.TypedClient) (map[string]time.Time, error) {
+	res, err := client.Cluster.State().Metric("metadata").FilterPath("metadata.indices.*.rollover_info").Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRolloverDates(res)
+}
+
+// parseRolloverDates parses the rollover info of the indices from the cluster
+// state metadata. The rollover info contains an entry per alias or data stream
+// the index has been rolled over for, the latest one is used.
+func parseRolloverDates(clusterState json.RawMessage) (map[string]time.Time, error) {
+	var state struct {
+		Metadata struct {
+			Indices map[string]struct {
+				RolloverInfo map[string]struct {
+					Time int64 `json:"time"`
+				} `json:"rollover_info"`
+			} `json:"indices"`
+		} `json:"metadata"`
+	}
+
+	err := json.Unmarshal(clusterState, &state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rollover info: %w", err)
+	}
+
+	rolloverDates := make(map[string]time.Time, len(state.Metadata.Indices))
+	for index, metadata := range state.Metadata.Indices {
+		for _, rollover := range metadata.RolloverInfo {
+			rolloverDate := time.UnixMilli(rollover.Time)
+			if rolloverDate.After(rolloverDates[index]) {
+				rolloverDates[index] = rolloverDate
+			}
+		}
+	}
+
+	return rolloverDates, nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseIndexSorts(t *testing.T) {
+	tests := []struct {
+		sorts []string
+
+		wantColumns   []string
+		wantAscending []bool
+		wantErr       bool
+	}{
+		{
+			sorts: []string{"age", "docs:asc", "pri-size:desc"},
+
+			wantColumns:   []string{"age", "docs", "pri-size"},
+			wantAscending: []bool{false, true, false},
+		},
+		{
+			sorts: []string{"policy"},
+
+			wantErr: true,
+		},
+		{
+			sorts: []string{"unknown"},
+
+			wantErr: true,
+		},
+		{
+			sorts: []string{"age:up"},
+
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.sorts[0], func(t *testing.T) {
+			sorts, err := parseIndexSorts(tc.sorts)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, sorts, len(tc.wantColumns))
+			for i, s := range sorts {
+				require.Equal(t, tc.wantColumns[i], s.column.name)
+				require.Equal(t, tc.wantAscending[i], s.ascending)
+			}
+		})
+	}
+}
+
+func Test_sortIndexDetails(t *testing.T) {
+	indices := []indexDetails{
+		{name: "c", phase: "warm", docs: 10, priSize: 1},
+		{name: "a", phase: "hot", docs: 20, priSize: 1},
+		{name: "b", phase: "warm", docs: 5, priSize: 2},
+	}
+
+	sorts, err := parseIndexSorts([]string{"docs:asc"})
+	require.NoError(t, err)
+
+	sortIndexDetails(indices, true, sorts)
+	require.Equal(t, []string{"a", "b", "c"}, indexNames(indices))
+
+	sorts, err = parseIndexSorts([]string{"pri-size", "docs"})
+	require.NoError(t, err)
+
+	sortIndexDetails(indices, false, sorts)
+	require.Equal(t, []string{"b", "a", "c"}, indexNames(indices))
+}
+
+func indexNames(indices []indexDetails) []string {
+	names := make([]string, 0, len(indices))
+	for _, index := range indices {
+		names = append(names, index.name)
+	}
+
+	return names
+}
+
+func Test_renderIndexColumns(t *testing.T) {
+	columns, err := selectIndexColumns([]string{"index", "docs", "avg-shard-size", "tier", "rollover-date", "next-phase", "next-phase-min-age"})
+	require.NoError(t, err)
+
+	indices := []indexDetails{
+		{name: "logs-1", docs: 100, priShards: 2, replicaShards: 2, totalSize: 4000, tiers: []string{"hot", "warm"}, rolloverDate: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), nextPhase: "cold", nextPhaseMinAge: 30 * day},
+		{name: "logs-2"},
+	}
+
+	buf := &bytes.Buffer{}
+	err = renderIndexColumns(buf, "csv", columns, indices)
+	require.NoError(t, err)
+
+	require.Equal(t, `index,docs,avg_shard_size_bytes,tier,rollover_date,next_phase,next_phase_min_age_seconds
+logs-1,100,1000,"hot,warm",2025-01-02T03:04:05Z,cold,2592000
+logs-2,0,0,,,,0
+`, buf.String())
+
+	_, err = selectIndexColumns([]string{"unknown"})
+	require.Error(t, err)
+}
+
+func Test_parseRolloverDates(t *testing.T) {
+	clusterState := []byte(`{
+  "metadata": {
+    "indices": {
+      ".ds-logs-2025.01.01-000001": {
+        "rollover_info": {
+          "logs": {"met_conditions": {"max_age": "1d"}, "time": 1735779845000}
+        }
+      },
+      "app-000001": {
+        "rollover_info": {
+          "app-old": {"time": 1735689600000},
+          "app": {"time": 1735779845000}
+        }
+      }
+    }
+  }
+}`)
+
+	rolloverDates, err := parseRolloverDates(clusterState)
+	require.NoError(t, err)
+
+	want := time.Date(2025, 1, 2, 1, 4, 5, 0, time.UTC)
+	require.Len(t, rolloverDates, 2)
+	require.True(t, want.Equal(rolloverDates[".ds-logs-2025.01.01-000001"]))
+	require.True(t, want.Equal(rolloverDates["app-000001"]))
+}
+
+func Test_nextPhase(t *testing.T) {
+	policy := types.IlmPolicy{
+		Phases: types.Phases{
+			Hot:    &types.Phase{},
+			Cold:   &types.Phase{MinAge: "30d"},
+			Delete: &types.Phase{MinAge: "90d"},
+		},
+	}
+
+	tests := []struct {
+		phase string
+
+		wantPhase  string
+		wantMinAge time.Duration
+	}{
+		{phase: "new", wantPhase: "hot"},
+		{phase: "hot", wantPhase: "cold", wantMinAge: 30 * day},
+		{phase: "warm", wantPhase: "cold", wantMinAge: 30 * day},
+		{phase: "cold", wantPhase: "delete", wantMinAge: 90 * day},
+		{phase: "delete", wantPhase: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.phase, func(t *testing.T) {
+			phase, minAge, err := nextPhase(policy, tc.phase)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPhase, phase)
+			require.Equal(t, tc.wantMinAge, minAge)
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/urfave/cli/v3"
+)
+
+// indexDetails contains the ILM state and the size of an ILM managed index.
+// failed is true, if ILM reports an error for the index. replicaShards is the
+// number of replica shards over all primary shards. tiers, rolloverDate,
+// nextPhase and nextPhaseMinAge are only filled by addIndexDetailsExtras.
+type indexDetails struct {
+	name            string
+	dataStream      string
+	writeIndex      bool
+	phase           string
+	action          string
+	step            string
+	policy          string
+	failedStep      string
+	failed          bool
+	age             time.Duration
+	priSize         int64
+	totalSize       int64
+	docs            int64
+	priShards       int64
+	replicaShards   int64
+	lifecycleDate   time.Time
+	timeInPhase     time.Duration
+	tiers           []string
+	rolloverDate    time.Time
+	nextPhase       string
+	nextPhaseMinAge time.Duration
+}
+
+// indexFilter contains the criteria an ILM managed index needs to match in
+// order to be selected. Empty values match all indices.
+type indexFilter struct {
+	action       string
+	phases       []string
+	ilmPolicies  []string
+	dataStream   string
+	indexRegex   *regexp.Regexp
+	excludeRegex *regexp.Regexp
+	failedOnly   bool
+	minAge       time.Duration
+	maxAge       time.Duration
+	minPriSize   int64
+	maxPriSize   int64
+	minTotalSize int64
+	maxTotalSize int64
+	where        indexPredicate
+}
+
+// indexFilterFlags returns the flags used to select ILM managed indices, which
+// are shared between the ilm commands.
+func indexFilterFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "action",
+			Aliases: []string{"a"},
+			Usage:   "Filter to only include indices in the given action",
+		},
+		&cli.StringSliceFlag{
+			Name:    "phase",
+			Aliases: []string{"p"},
+			Usage:   "Filter to only include indices in the given phases",
+		},
+		&cli.StringSliceFlag{
+			Name:    "ilm-policy",
+			Aliases: []string{"i"},
+			Usage:   "Filter to only include indices with one of the given ILM policies attached",
+		},
+		&cli.StringFlag{
+			Name:  "data-stream",
+			Usage: "Filter to only include the backing indices of the given data stream",
+		},
+		&cli.StringFlag{
+			Name:  "index-regex",
+			Usage: "Filter to only include indices with the name matching the given regular expression",
+		},
+		&cli.StringFlag{
+			Name:  "exclude-regex",
+			Usage: "Filter to exclude indices with the name matching the given regular expression",
+		},
+		&cli.BoolFlag{
+			Name:  "failed-only",
+			Usage: "Filter to only include indices, for which ILM reports an error (failed step or error in step info)",
+		},
+		&cli.StringFlag{
+			Name:  "min-total-size",
+			Usage: "Minimum total size (primary shard + replicas) of index in order to be contained in the result, supported units: k, m, g, t, p",
+		},
+		&cli.StringFlag{
+			Name:  "max-total-size",
+			Usage: "Maximum total size (primary shard + replicas) of index in order to be contained in the result, supported units: k, m, g, t, p",
+		},
+		&cli.StringFlag{
+			Name:  "min-pri-size",
+			Usage: "Minimum primary shard size of index in order to be contained in the result, supported units: k, m, g, t, p",
+		},
+		&cli.StringFlag{
+			Name:  "max-pri-size",
+			Usage: "Maximum primary shard size of index in order to be contained in the result, supported units: k, m, g, t, p",
+		},
+		&cli.IntFlag{
+			Name:  "min-age-days",
+			Usage: "Minimum age of index in days in order to be contained in the result",
+		},
+		&cli.IntFlag{
+			Name:  "max-age-days",
+			Usage: "Maximum age of index in days in order to be contained in the result",
+		},
+		&cli.StringFlag{
+			Name:  "where",
+			Usage: "Filter expression over the index fields, e.g. 'age > 30d && pri-size < 1g', fields: name, data-stream, phase, action, step, policy, failed-step, age, pri-size, total-size, write-index, failed",
+		},
+	}
+}
+
+// newIndexFilter returns the indexFilter for the flags returned by
+// indexFilterFlags.
+func newIndexFilter(cmd *cli.Command) (indexFilter, error) {
+	filter := indexFilter{
+		action:      cmd.String("action"),
+		phases:      cmd.StringSlice("phase"),
+		ilmPolicies: cmd.StringSlice("ilm-policy"),
+		dataStream:  cmd.String("data-stream"),
+		failedOnly:  cmd.Bool("failed-only"),
+		minAge:      time.Duration(cmd.Int("min-age-days")) * 24 * time.Hour,
+		maxAge:      time.Duration(cmd.Int("max-age-days")) * 24 * time.Hour,
+	}
+
+	var err error
+	if regex := cmd.String("index-regex"); regex != "" {
+		filter.indexRegex, err = regexp.Compile(regex)
+		if err != nil {
+			return indexFilter{}, fmt.Errorf("failed to parse index regex: %w", err)
+		}
+	}
+
+	if regex := cmd.String("exclude-regex"); regex != "" {
+		filter.excludeRegex, err = regexp.Compile(regex)
+		if err != nil {
+			return indexFilter{}, fmt.Errorf("failed to parse exclude regex: %w", err)
+		}
+	}
+
+	sizes := []struct {
+		flag string
+		size *int64
+	}{
+		{flag: "min-pri-size", size: &filter.minPriSize},
+		{flag: "max-pri-size", size: &filter.maxPriSize},
+		{flag: "min-total-size", size: &filter.minTotalSize},
+		{flag: "max-total-size", size: &filter.maxTotalSize},
+	}
+	for _, size := range sizes {
+		sizeStr := cmd.String(size.flag)
+		if sizeStr == "" {
+			continue
+		}
+
+		*size.size, err = units.FromHumanSize(sizeStr)
+		if err != nil {
+			return indexFilter{}, fmt.Errorf("failed to parse %s: %w", size.flag, err)
+		}
+	}
+
+	if where := cmd.String("where"); where != "" {
+		filter.where, err = parseWhere(where)
+		if err != nil {
+			return indexFilter{}, err
+		}
+	}
+
+	return filter, nil
+}
+
+// isEmpty returns true, if the filter does not contain any criteria. The
+// slices are checked by length, since the string slice flags are empty, not
+// nil, if not set.
+func (f indexFilter) isEmpty() bool {
+	return f.action == "" &&
+		len(f.phases) == 0 &&
+		len(f.ilmPolicies) == 0 &&
+		f.dataStream == "" &&
+		f.indexRegex == nil &&
+		f.excludeRegex == nil &&
+		!f.failedOnly &&
+		f.minAge == 0 &&
+		f.maxAge == 0 &&
+		f.minPriSize == 0 &&
+		f.maxPriSize == 0 &&
+		f.minTotalSize == 0 &&
+		f.maxTotalSize == 0 &&
+		f.where == nil
+}
+
+// matches returns true, if the index matches all criteria of the filter.
+func (f indexFilter) matches(index indexDetails) bool {
+	if f.action != "" && f.action != index.action {
+		return false
+	}
+
+	if len(f.phases) > 0 && !slices.Contains(f.phases, index.phase) {
+		return false
+	}
+
+	if len(f.ilmPolicies) > 0 && !slices.Contains(f.ilmPolicies, index.policy) {
+		return false
+	}
+
+	if f.dataStream != "" && f.dataStream != index.dataStream {
+		return false
+	}
+
+	if f.indexRegex != nil && !f.indexRegex.MatchString(index.name) {
+		return false
+	}
+
+	if f.excludeRegex != nil && f.excludeRegex.MatchString(index.name) {
+		return false
+	}
+
+	if f.failedOnly && !index.failed {
+		return false
+	}
+
+	if index.priSize < f.minPriSize || (f.maxPriSize > 0 && index.priSize > f.maxPriSize) {
+		return false
+	}
+
+	if index.totalSize < f.minTotalSize || (f.maxTotalSize > 0 && index.totalSize > f.maxTotalSize) {
+		return false
+	}
+
+	if index.age < f.minAge || (f.maxAge > 0 && index.age > f.maxAge) {
+		return false
+	}
+
+	if f.where != nil && !f.where(index) {
+		return false
+	}
+
+	return true
+}
+
+// getIndexDetails returns the indexDetails of all ILM managed indices matching
+// the index pattern and the filter. The index pattern may also contain the
+// name of a data stream, which matches all its backing indices.
+func getIndexDetails(ctx context.Context, client *elasticsearch.TypedClient, indexPattern string, filter indexFilter) ([]indexDetails, error) {
+	stats, err := getIndexStats(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	backing, err := getBackingIndices(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	ilms, err := client.Ilm.ExplainLifecycle(indexPattern).OnlyManaged(true).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	indices := make([]indexDetails, 0, len(ilms.Indices))
+	for index, ilm := range ilms.Indices {
+		managed, ok := ilm.(*types.LifecycleExplainManaged)
+		if !ok {
+			continue
+		}
+
+		age, err := parseESDuration(managed.Age)
+		if err != nil {
+			return nil, err
+		}
+
+		details := indexDetails{
+			name:          index,
+			dataStream:    backing[index].dataStream,
+			writeIndex:    backing[index].writeIndex,
+			phase:         deref(managed.Phase),
+			action:        deref(managed.Action),
+			step:          deref(managed.Step),
+			policy:        deref(managed.Policy),
+			failedStep:    deref(managed.FailedStep),
+			failed:        isFailed(managed),
+			age:           age,
+			priSize:       stats[index].priSize,
+			totalSize:     stats[index].totalSize,
+			docs:          stats[index].docs,
+			priShards:     stats[index].priShards,
+			replicaShards: stats[index].replicaShards,
+		}
+
+		if managed.LifecycleDateMillis != nil {
+			details.lifecycleDate = time.UnixMilli(*managed.LifecycleDateMillis)
+		}
+
+		if managed.PhaseTimeMillis != nil {
+			details.timeInPhase = now.Sub(time.UnixMilli(*managed.PhaseTimeMillis))
+		}
+
+		if !filter.matches(details) {
+			continue
+		}
+
+		indices = append(indices, details)
+	}
+
+	return indices, nil
+}
+
+// isFailed returns true, if ILM reports an error for the index, either by the
+// ERROR step, a failed step or an error in the step info.
+func isFailed(managed *types.LifecycleExplainManaged) bool {
+	if deref(managed.Step) == "ERROR" || deref(managed.FailedStep) != "" {
+		return true
+	}
+
+	_, ok := managed.StepInfo["type"]
+	return ok
+}
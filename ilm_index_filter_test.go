@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func Test_indexFilter_matches(t *testing.T) {
+	index := indexDetails{
+		name:       ".ds-logs-2025.01.01-000001",
+		dataStream: "logs",
+		phase:      "warm",
+		action:     "complete",
+		step:       "complete",
+		policy:     "logs",
+		age:        30 * day,
+		priSize:    10 * 1024 * 1024 * 1024,
+		totalSize:  20 * 1024 * 1024 * 1024,
+	}
+
+	tests := []struct {
+		name   string
+		filter indexFilter
+
+		wantMatch bool
+	}{
+		{
+			name:   "empty filter",
+			filter: indexFilter{},
+
+			wantMatch: true,
+		},
+		{
+			name:   "all criteria matching",
+			filter: indexFilter{action: "complete", phases: []string{"hot", "warm"}, ilmPolicies: []string{"logs"}, dataStream: "logs", minAge: 30 * day, minPriSize: 10 * 1024 * 1024 * 1024, minTotalSize: 20 * 1024 * 1024 * 1024},
+
+			wantMatch: true,
+		},
+		{
+			name:   "other phase",
+			filter: indexFilter{phases: []string{"hot", "cold"}},
+
+			wantMatch: false,
+		},
+		{
+			name:   "other policy",
+			filter: indexFilter{ilmPolicies: []string{"metrics"}},
+
+			wantMatch: false,
+		},
+		{
+			name:   "other data stream",
+			filter: indexFilter{dataStream: "metrics"},
+
+			wantMatch: false,
+		},
+		{
+			name:   "too young",
+			filter: indexFilter{minAge: 31 * day},
+
+			wantMatch: false,
+		},
+		{
+			name:   "too small",
+			filter: indexFilter{minTotalSize: 21 * 1024 * 1024 * 1024},
+
+			wantMatch: false,
+		},
+		{
+			name:   "too old",
+			filter: indexFilter{maxAge: 29 * day},
+
+			wantMatch: false,
+		},
+		{
+			name:   "too large",
+			filter: indexFilter{maxPriSize: 9 * 1024 * 1024 * 1024},
+
+			wantMatch: false,
+		},
+		{
+			name:   "within max thresholds",
+			filter: indexFilter{maxAge: 30 * day, maxPriSize: 10 * 1024 * 1024 * 1024, maxTotalSize: 20 * 1024 * 1024 * 1024},
+
+			wantMatch: true,
+		},
+		{
+			name:   "index regex not matching",
+			filter: indexFilter{indexRegex: regexp.MustCompile(`^metrics-`)},
+
+			wantMatch: false,
+		},
+		{
+			name:   "excluded by regex",
+			filter: indexFilter{excludeRegex: regexp.MustCompile(`^\.ds-logs-`)},
+
+			wantMatch: false,
+		},
+		{
+			name:   "not failed",
+			filter: indexFilter{failedOnly: true},
+
+			wantMatch: false,
+		},
+		{
+			name:   "where not matching",
+			filter: indexFilter{where: func(index indexDetails) bool { return false }},
+
+			wantMatch: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.wantMatch, tc.filter.matches(index))
+		})
+	}
+}
+
+func Test_indexFilter_isEmpty(t *testing.T) {
+	require.True(t, indexFilter{}.isEmpty())
+	require.False(t, indexFilter{phases: []string{"warm"}}.isEmpty())
+	require.False(t, indexFilter{indexRegex: regexp.MustCompile(`.*`)}.isEmpty())
+}
+
+func Test_newIndexFilter_isEmpty(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+
+		wantEmpty bool
+	}{
+		{
+			name: "no filter flags",
+
+			wantEmpty: true,
+		},
+		{
+			name: "phase",
+			args: []string{"--phase", "warm"},
+
+			wantEmpty: false,
+		},
+		{
+			name: "ilm policy",
+			args: []string{"--ilm-policy", "logs"},
+
+			wantEmpty: false,
+		},
+		{
+			name: "min age",
+			args: []string{"--min-age-days", "30"},
+
+			wantEmpty: false,
+		},
+		{
+			name: "where",
+			args: []string{"--where", "age > 30d"},
+
+			wantEmpty: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var filter indexFilter
+			cmd := &cli.Command{
+				Name:  "test",
+				Flags: indexFilterFlags(),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					var err error
+					filter, err = newIndexFilter(cmd)
+					return err
+				},
+			}
+
+			err := cmd.Run(context.Background(), append([]string{"test"}, tc.args...))
+			require.NoError(t, err)
+			require.Equal(t, tc.wantEmpty, filter.isEmpty())
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+)
+
+// indexGroupKeys are the supported keys to group indices by.
+var indexGroupKeys = []string{"policy", "phase", "action", "data-stream"}
+
+// indexGroup contains the aggregated values of a group of indices.
+type indexGroup struct {
+	key       string
+	count     int
+	priSize   int64
+	totalSize int64
+	oldest    time.Duration
+	youngest  time.Duration
+}
+
+// indexGroupKey returns the value of the index for the given group key.
+func indexGroupKey(index indexDetails, groupBy string) string {
+	switch groupBy {
+	case "policy":
+		return index.policy
+	case "phase":
+		return index.phase
+	case "action":
+		return index.action
+	case "data-stream":
+		return index.dataStream
+	}
+
+	return ""
+}
+
+// groupIndexDetails aggregates the indices by the given group key. The groups
+// are sorted by phase order for phase and by key for all other group keys.
+func groupIndexDetails(indices []indexDetails, groupBy string) []indexGroup {
+	groupsByKey := make(map[string]*indexGroup)
+	for _, index := range indices {
+		key := indexGroupKey(index, groupBy)
+
+		group, ok := groupsByKey[key]
+		if !ok {
+			group = &indexGroup{key: key, oldest: index.age, youngest: index.age}
+			groupsByKey[key] = group
+		}
+
+		group.count++
+		group.priSize += index.priSize
+		group.totalSize += index.totalSize
+		group.oldest = max(group.oldest, index.age)
+		group.youngest = min(group.youngest, index.age)
+	}
+
+	groups := make([]indexGroup, 0, len(groupsByKey))
+	for _, group := range groupsByKey {
+		groups = append(groups, *group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groupBy == "phase" && groups[i].key != groups[j].key {
+			return phaseLess(groups[i].key, groups[j].key)
+		}
+
+		return groups[i].key < groups[j].key
+	})
+
+	return groups
+}
+
+// indexGroupColumns returns the columns of the aggregated indices grouped by
+// the given group key. Sizes are given in bytes and the ages in seconds in the
+// machine readable formats.
+func indexGroupColumns(groupBy string) []column[indexGroup] {
+	var header string
+	switch groupBy {
+	case "policy":
+		header = "Policy"
+	case "phase":
+		header = "Phase"
+	case "action":
+		header = "Action"
+	case "data-stream":
+		header = "Data Stream"
+	}
+
+	return []column[indexGroup]{
+		{
+			header: header, key: strings.ReplaceAll(groupBy, "-", "_"),
+			value: func(g indexGroup) string {
+				if g.key == "" {
+					return "-"
+				}
+
+				return g.key
+			},
+			raw: func(g indexGroup) any { return g.key },
+		},
+		{
+			header: "Indices", key: "indices",
+			value: func(g indexGroup) string { return strconv.Itoa(g.count) },
+			raw:   func(g indexGroup) any { return g.count },
+		},
+		{
+			header: "Pri Size", key: "pri_size_bytes",
+			value: func(g indexGroup) string { return units.BytesSize(float64(g.priSize)) },
+			raw:   func(g indexGroup) any { return g.priSize },
+		},
+		{
+			header: "Total Size", key: "total_size_bytes",
+			value: func(g indexGroup) string { return units.BytesSize(float64(g.totalSize)) },
+			raw:   func(g indexGroup) any { return g.totalSize },
+		},
+		{
+			header: "Oldest", key: "oldest_age_seconds",
+			value: func(g indexGroup) string { return formatDuration(g.oldest) },
+			raw:   func(g indexGroup) any { return int64(g.oldest.Seconds()) },
+		},
+		{
+			header: "Youngest", key: "youngest_age_seconds",
+			value: func(g indexGroup) string { return formatDuration(g.youngest) },
+			raw:   func(g indexGroup) any { return int64(g.youngest.Seconds()) },
+		},
+	}
+}
+
+// validateGroupBy returns an error, if groupBy is not one of indexGroupKeys.
+func validateGroupBy(groupBy string) error {
+	if groupBy != "" && !slices.Contains(indexGroupKeys, groupBy) {
+		return fmt.Errorf("group by %q is not supported, use one of %v", groupBy, indexGroupKeys)
+	}
+
+	return nil
+}
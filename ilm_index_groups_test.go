@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_groupIndexDetails(t *testing.T) {
+	indices := []indexDetails{
+		{name: ".ds-logs-2025.01.01-000001", dataStream: "logs", phase: "warm", policy: "logs", age: 10 * day, priSize: 10, totalSize: 20},
+		{name: ".ds-logs-2025.01.02-000002", dataStream: "logs", writeIndex: true, phase: "hot", policy: "logs", age: time.Hour, priSize: 5, totalSize: 10},
+		{name: "metrics-000001", phase: "warm", policy: "metrics", age: 3 * day, priSize: 1, totalSize: 2},
+	}
+
+	tests := []struct {
+		groupBy string
+
+		want []indexGroup
+	}{
+		{
+			groupBy: "phase",
+
+			want: []indexGroup{
+				{key: "hot", count: 1, priSize: 5, totalSize: 10, oldest: time.Hour, youngest: time.Hour},
+				{key: "warm", count: 2, priSize: 11, totalSize: 22, oldest: 10 * day, youngest: 3 * day},
+			},
+		},
+		{
+			groupBy: "policy",
+
+			want: []indexGroup{
+				{key: "logs", count: 2, priSize: 15, totalSize: 30, oldest: 10 * day, youngest: time.Hour},
+				{key: "metrics", count: 1, priSize: 1, totalSize: 2, oldest: 3 * day, youngest: 3 * day},
+			},
+		},
+		{
+			groupBy: "data-stream",
+
+			want: []indexGroup{
+				{key: "", count: 1, priSize: 1, totalSize: 2, oldest: 3 * day, youngest: 3 * day},
+				{key: "logs", count: 2, priSize: 15, totalSize: 30, oldest: 10 * day, youngest: time.Hour},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.groupBy, func(t *testing.T) {
+			require.Equal(t, tc.want, groupIndexDetails(indices, tc.groupBy))
+		})
+	}
+}
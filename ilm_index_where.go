@@ -0,0 +1,370 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/docker/go-units"
+)
+
+// indexPredicate returns true, if the index matches the condition.
+type indexPredicate func(index indexDetails) bool
+
+// whereFields are the fields of indexDetails, which can be used in the
+// expressions of --where, by field type.
+var whereFields = map[string]string{
+	"name":        "string",
+	"data-stream": "string",
+	"phase":       "string",
+	"action":      "string",
+	"step":        "string",
+	"policy":      "string",
+	"failed-step": "string",
+	"age":         "duration",
+	"pri-size":    "size",
+	"total-size":  "size",
+	"write-index": "bool",
+	"failed":      "bool",
+}
+
+// parseWhere parses a filter expression over the fields of indexDetails, e.g.
+// `age > 30d && pri-size < 1g`. The expression consists of comparisons
+// `<field> <operator> <value>`, which can be combined with `&&`, `||`, `!` and
+// parentheses. Supported operators are:
+//   - string fields: ==, !=, =~ (regular expression), !~
+//   - age and size fields: ==, !=, <, <=, >, >=
+//   - bool fields: ==, !=
+//
+// Values containing whitespace or operator characters need to be quoted with
+// single or double quotes.
+func parseWhere(expr string) (indexPredicate, error) {
+	tokens, err := tokenizeWhere(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &whereParser{tokens: tokens}
+
+	predicate, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid where expression %q: %w", expr, err)
+	}
+
+	if !p.done() {
+		return nil, fmt.Errorf("invalid where expression %q: unexpected %q", expr, p.peek().value)
+	}
+
+	return predicate, nil
+}
+
+// whereToken is a token of a where expression. Quoted values are always of
+// kind value, even if their content looks like an operator.
+type whereToken struct {
+	value  string
+	quoted bool
+}
+
+// whereOperators are the operators of the where expressions. Longer operators
+// are listed first, since the tokenizer uses the first matching operator.
+var whereOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")"}
+
+// tokenizeWhere splits a where expression into tokens.
+func tokenizeWhere(expr string) ([]whereToken, error) {
+	var tokens []whereToken
+
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid where expression %q: unterminated quote at position %d", expr, i)
+			}
+
+			tokens = append(tokens, whereToken{value: expr[i+1 : i+1+end], quoted: true})
+			i += end + 2
+
+		default:
+			operator := ""
+			for _, op := range whereOperators {
+				if strings.HasPrefix(expr[i:], op) {
+					operator = op
+					break
+				}
+			}
+
+			if operator != "" {
+				tokens = append(tokens, whereToken{value: operator})
+				i += len(operator)
+				continue
+			}
+
+			end := i
+			for end < len(expr) && !strings.ContainsRune(" \t\n\"'&|=!<>~()", rune(expr[end])) {
+				end++
+			}
+
+			if end == i {
+				return nil, fmt.Errorf("invalid where expression %q: unexpected %q at position %d", expr, expr[i], i)
+			}
+
+			tokens = append(tokens, whereToken{value: expr[i:end]})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+// whereParser is a recursive descent parser for where expressions.
+type whereParser struct {
+	tokens []whereToken
+	pos    int
+}
+
+func (p *whereParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *whereParser) peek() whereToken {
+	if p.done() {
+		return whereToken{}
+	}
+
+	return p.tokens[p.pos]
+}
+
+// accept consumes the next token, if it is the given operator.
+func (p *whereParser) accept(operator string) bool {
+	token := p.peek()
+	if p.done() || token.quoted || token.value != operator {
+		return false
+	}
+
+	p.pos++
+	return true
+}
+
+func (p *whereParser) next() (whereToken, error) {
+	if p.done() {
+		return whereToken{}, fmt.Errorf("unexpected end of expression")
+	}
+
+	token := p.tokens[p.pos]
+	p.pos++
+	return token, nil
+}
+
+func (p *whereParser) parseOr() (indexPredicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(index indexDetails) bool { return l(index) || right(index) }
+	}
+
+	return left, nil
+}
+
+func (p *whereParser) parseAnd() (indexPredicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(index indexDetails) bool { return l(index) && right(index) }
+	}
+
+	return left, nil
+}
+
+func (p *whereParser) parseUnary() (indexPredicate, error) {
+	if p.accept("!") {
+		predicate, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return func(index indexDetails) bool { return !predicate(index) }, nil
+	}
+
+	if p.accept("(") {
+		predicate, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+
+		return predicate, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *whereParser) parseComparison() (indexPredicate, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	fieldType, ok := whereFields[field.value]
+	if !ok || field.quoted {
+		return nil, fmt.Errorf("unknown field %q", field.value)
+	}
+
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch fieldType {
+	case "string":
+		return stringComparison(field.value, operator.value, value.value)
+	case "bool":
+		return boolComparison(field.value, operator.value, value.value)
+	}
+
+	return numberComparison(field.value, fieldType, operator.value, value.value)
+}
+
+func stringComparison(field string, operator string, value string) (indexPredicate, error) {
+	get := func(index indexDetails) string {
+		switch field {
+		case "name":
+			return index.name
+		case "data-stream":
+			return index.dataStream
+		case "phase":
+			return index.phase
+		case "action":
+			return index.action
+		case "step":
+			return index.step
+		case "policy":
+			return index.policy
+		case "failed-step":
+			return index.failedStep
+		}
+
+		return ""
+	}
+
+	switch operator {
+	case "==":
+		return func(index indexDetails) bool { return get(index) == value }, nil
+	case "!=":
+		return func(index indexDetails) bool { return get(index) != value }, nil
+	case "=~", "!~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression for field %q: %w", field, err)
+		}
+
+		match := operator == "=~"
+		return func(index indexDetails) bool { return re.MatchString(get(index)) == match }, nil
+	}
+
+	return nil, fmt.Errorf("operator %q is not supported for field %q", operator, field)
+}
+
+func boolComparison(field string, operator string, value string) (indexPredicate, error) {
+	want, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q for field %q: %w", value, field, err)
+	}
+
+	get := func(index indexDetails) bool {
+		if field == "write-index" {
+			return index.writeIndex
+		}
+
+		return index.failed
+	}
+
+	switch operator {
+	case "==":
+		return func(index indexDetails) bool { return get(index) == want }, nil
+	case "!=":
+		return func(index indexDetails) bool { return get(index) != want }, nil
+	}
+
+	return nil, fmt.Errorf("operator %q is not supported for field %q", operator, field)
+}
+
+func numberComparison(field string, fieldType string, operator string, value string) (indexPredicate, error) {
+	var want int64
+	switch fieldType {
+	case "duration":
+		age, err := parseESDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for field %q: %w", value, field, err)
+		}
+
+		want = int64(age)
+
+	case "size":
+		size, err := units.FromHumanSize(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for field %q: %w", value, field, err)
+		}
+
+		want = size
+	}
+
+	get := func(index indexDetails) int64 {
+		switch field {
+		case "age":
+			return int64(index.age)
+		case "pri-size":
+			return index.priSize
+		case "total-size":
+			return index.totalSize
+		}
+
+		return 0
+	}
+
+	switch operator {
+	case "==":
+		return func(index indexDetails) bool { return get(index) == want }, nil
+	case "!=":
+		return func(index indexDetails) bool { return get(index) != want }, nil
+	case "<":
+		return func(index indexDetails) bool { return get(index) < want }, nil
+	case "<=":
+		return func(index indexDetails) bool { return get(index) <= want }, nil
+	case ">":
+		return func(index indexDetails) bool { return get(index) > want }, nil
+	case ">=":
+		return func(index indexDetails) bool { return get(index) >= want }, nil
+	}
+
+	return nil, fmt.Errorf("operator %q is not supported for field %q", operator, field)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseWhere(t *testing.T) {
+	index := indexDetails{
+		name:      ".ds-logs-2025.01.01-000001",
+		phase:     "warm",
+		action:    "complete",
+		step:      "complete",
+		policy:    "logs",
+		age:       40 * day,
+		priSize:   500 * 1000 * 1000,
+		totalSize: 1000 * 1000 * 1000,
+	}
+
+	tests := []struct {
+		expr string
+
+		wantMatch bool
+		wantErr   bool
+	}{
+		{expr: "age > 30d && pri-size < 1g", wantMatch: true},
+		{expr: "age>30d&&pri-size<1g", wantMatch: true},
+		{expr: "age <= 30d || total-size >= 1g", wantMatch: true},
+		{expr: "age < 1h", wantMatch: false},
+		{expr: "phase == warm && !(policy == metrics)", wantMatch: true},
+		{expr: "phase != warm || failed == true", wantMatch: false},
+		{expr: `name =~ '^\.ds-logs-'`, wantMatch: true},
+		{expr: `name !~ "^\.ds-"`, wantMatch: false},
+		{expr: "write-index == false", wantMatch: true},
+		{expr: "phase == hot || phase == warm && age > 365d", wantMatch: false},
+		{expr: "(phase == hot || phase == warm) && age > 720h", wantMatch: true},
+		{expr: "unknown == 1", wantErr: true},
+		{expr: "age > 30d &&", wantErr: true},
+		{expr: "(age > 30d", wantErr: true},
+		{expr: "age =~ 30d", wantErr: true},
+		{expr: "pri-size < large", wantErr: true},
+		{expr: "name == 'unterminated", wantErr: true},
+		{expr: "phase == warm warm", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.expr, func(t *testing.T) {
+			predicate, err := parseWhere(tc.expr)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantMatch, predicate(index))
+		})
+	}
+}
+
+func Test_parseWhere_age(t *testing.T) {
+	predicate, err := parseWhere("age == 36h")
+	require.NoError(t, err)
+
+	require.True(t, predicate(indexDetails{age: 36 * time.Hour}))
+}
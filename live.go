@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+)
+
+// liveRenderer writes output, which is refreshed periodically. If the writer
+// is a terminal, the previous output is replaced in place, otherwise the
+// output is appended.
+type liveRenderer struct {
+	w       io.Writer
+	inPlace bool
+	lines   int
+}
+
+func newLiveRenderer(w io.Writer) *liveRenderer {
+	return &liveRenderer{
+		w:       w,
+		inPlace: isTerminal(w),
+	}
+}
+
+// Render replaces the previously rendered output with content.
+func (r *liveRenderer) Render(content string) {
+	if r.inPlace && r.lines > 0 {
+		// Move the cursor up to the start of the previous output and clear
+		// everything below.
+		fmt.Fprintf(r.w, "\033[%dA\033[J", r.lines)
+	}
+
+	fmt.Fprint(r.w, content)
+	r.lines = strings.Count(content, "\n")
+}
+
+// watch calls refresh every interval and renders its output with a
+// liveRenderer until the context is canceled. If refresh returns an error,
+// the error is rendered instead of the output and the refresh is retried in
+// the next interval.
+func watch(ctx context.Context, w io.Writer, interval time.Duration, refresh func(ctx context.Context, w io.Writer) error) error {
+	live := newLiveRenderer(w)
+	for {
+		header := fmt.Sprintf("Every %s, last refresh: %s\n\n", interval, time.Now().Format(time.TimeOnly))
+
+		buf := &strings.Builder{}
+		err := refresh(ctx, buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			buf.Reset()
+			fmt.Fprintf(buf, "ERROR: refresh failed, retrying in %s: %v\n", interval, err)
+		}
+
+		live.Render(header + buf.String())
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// isTerminal returns true, if w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_watch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var refreshes int
+	w := &bytes.Buffer{}
+	err := watch(ctx, w, time.Millisecond, func(ctx context.Context, w io.Writer) error {
+		refreshes++
+		switch refreshes {
+		case 1:
+			return errors.New("connection refused")
+		case 3:
+			cancel()
+		}
+
+		fmt.Fprintf(w, "refresh %d\n", refreshes)
+		return nil
+	})
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 3, refreshes)
+	require.Contains(t, w.String(), "ERROR: refresh failed, retrying in 1ms: connection refused")
+	require.Contains(t, w.String(), "refresh 2")
+	require.Contains(t, w.String(), "refresh 3")
+}
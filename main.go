@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/urfave/cli/v3"
 )
@@ -24,7 +25,7 @@ func run(ctx context.Context, args []string) error {
 			{
 				Name:  "downscale",
 				Usage: "calculate, if downscaling of an EC deployment is feasible based on current disk consumption",
-				Flags: []cli.Flag{
+				Flags: append(templateSourceFlags(),
 					&cli.BoolFlag{
 						Name:    "exit-code",
 						Aliases: []string{"e"},
@@ -39,11 +40,10 @@ func run(ctx context.Context, args []string) error {
 						Local: true,
 					},
 					&cli.StringFlag{
-						Name:     "profile",
-						Aliases:  []string{"p"},
-						Usage:    "Deployment profile Username used to authenticate against Elasticsearch, e.g. azure-general-purpose-v2",
-						Local:    true,
-						Required: true,
+						Name:    "profile",
+						Aliases: []string{"p"},
+						Usage:   "Hardware profile of the deployment, e.g. azure-general-purpose-v2 (default: detected from the disk sizes of the nodes)",
+						Local:   true,
 					},
 					&cli.BoolFlag{
 						Name:    "recommend-zone-change",
@@ -52,7 +52,12 @@ func run(ctx context.Context, args []string) error {
 						Value:   false,
 						Local:   true,
 					},
-				},
+					&cli.DurationFlag{
+						Name:  "watch",
+						Usage: "Refresh the result every given interval (e.g. 30s) and show the change of the disk usage per tier since the last refresh, can not be combined with --exit-code",
+						Local: true,
+					},
+				),
 				Action: downscale,
 			},
 			{
@@ -62,38 +67,216 @@ func run(ctx context.Context, args []string) error {
 					{
 						Name:  "list",
 						Usage: "list ilm managed indices filtered by phase",
-						Flags: []cli.Flag{
+						Flags: append(indexFilterFlags(),
+							&cli.StringSliceFlag{
+								Name:    "sort",
+								Aliases: []string{"s"},
+								Usage:   "Sort indices by the given columns in the format <column>[:asc|:desc] (default order: desc), allowed columns are: age, pri-size, total-size, docs, pri-shards, replica-shards, avg-shard-size, lifecycle-date, phase-time, rollover-date, next-phase-min-age",
+							},
 							&cli.StringFlag{
-								Name:    "action",
-								Aliases: []string{"a"},
-								Usage:   "Filter to only include indices in the given action",
+								Name:    "format",
+								Aliases: []string{"f"},
+								Usage:   "Format for the result: table, compact, markdown, json, ndjson, csv (default: table), sizes are in bytes and the age in seconds for json, ndjson and csv",
+							},
+							&cli.StringSliceFlag{
+								Name:  "columns",
+								Usage: "Columns to show: index, data-stream, write-index, phase, action, step, policy, age, pri-size, total-size, docs, pri-shards, replica-shards, avg-shard-size, tier, lifecycle-date, phase-time, rollover-date, next-phase, next-phase-min-age (default: index, data-stream, write-index, phase, action, step, policy, age, pri-size, total-size)",
 							},
 							&cli.StringFlag{
-								Name:    "phase",
-								Aliases: []string{"p"},
-								Usage:   "Filter to only include indices in the given phase",
+								Name:    "group-by",
+								Aliases: []string{"g"},
+								Usage:   "Show the index count, sizes and oldest/youngest age aggregated by: policy, phase, action, data-stream instead of the individual indices",
+							},
+							&cli.BoolFlag{
+								Name:  "with-indices",
+								Usage: "Show the individual indices in addition to the aggregation of --group-by, only supported for the formats table, compact and markdown",
+							},
+							&cli.DurationFlag{
+								Name:  "watch",
+								Usage: "Refresh the result every given interval (e.g. 10s) and highlight the indices, which changed since the last refresh",
+							},
+						),
+						Action: ilmList,
+					},
+					{
+						Name:  "move",
+						Usage: "move ilm managed index to a different phase",
+						Flags: append(indexFilterFlags(),
+							&cli.BoolFlag{
+								Name:    "dry-run",
+								Aliases: []string{"n"},
+								Usage:   "Dry run, don't actually execute move operation",
+							},
+							&cli.BoolFlag{
+								Name:    "force",
+								Aliases: []string{"f"},
+								Usage:   "Try to move the index to the new phase even with pre condition checks (complete state, capacity of target tier) failing",
+							},
+							&cli.StringFlag{
+								Name:  "index-pattern",
+								Usage: "Name or pattern of the indices or name of a data stream, which should be moved to an other ILM tier, the write index of a data stream is never moved",
+								Value: "_all",
+							},
+							&cli.StringFlag{
+								Name:     "target-phase",
+								Usage:    "Name of the phase the index should be moved to, valid values: hot, warm, cold, frozen, delete",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "target-action",
+								Usage: "Name of the action within the target phase the index should be moved to, e.g. forcemerge, must be defined in the phase of the policy; allows to move backwards within the current phase",
+							},
+							&cli.StringFlag{
+								Name:  "target-step",
+								Usage: "Name of the step within the target action the index should be moved to, requires --target-action, supported for the actions allocate, delete, forcemerge, migrate, readonly, rollover, set_priority and wait_for_snapshot",
+							},
+							&cli.IntFlag{
+								Name:  "max-in-flight",
+								Usage: "Maximum number of move operations executed concurrently",
+								Value: 1,
+							},
+							&cli.IntFlag{
+								Name:  "batch-size",
+								Usage: "Number of indices moved per batch, between the batches the cluster is given time to settle (0: all indices in a single batch)",
+							},
+							&cli.DurationFlag{
+								Name:  "pause",
+								Usage: "Time to pause after each batch before waiting for the cluster to settle",
+							},
+							&cli.DurationFlag{
+								Name:  "settle-timeout",
+								Usage: "Maximum time to wait after a batch for the cluster to have no relocating or initializing shards and a health other than red",
+								Value: 30 * time.Minute,
+							},
+							&cli.Float64Flag{
+								Name:  "max-fill-pct",
+								Usage: "Maximum disk fill level of the target tier in percent after the move, the move is refused without --force if exceeded (the low disk watermark applies, if lower)",
+								Value: 85.0,
+							},
+							&cli.BoolFlag{
+								Name:  "wait",
+								Usage: "Wait until the moved indices have completed the target phase or failed, exit with none 0 exit code, if at least one index failed",
+							},
+							&cli.DurationFlag{
+								Name:  "timeout",
+								Usage: "Maximum time to wait for the moved indices with --wait",
+								Value: time.Hour,
+							},
+							&cli.StringFlag{
+								Name:  "journal",
+								Usage: "File the original ILM state of the moved indices is written to, which allows to undo the move with ilm undo (default: ec_check-ilm-move-<deployment>-<timestamp>.json)",
+							},
+							&cli.BoolFlag{
+								Name:  "i-know-this-deletes-data",
+								Usage: "Confirm to move indices to the delete phase, which deletes their data irreversibly",
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "Format for the list of indices to move: table, compact, markdown (default: table)",
+							},
+						),
+						Action: ilmMove,
+					},
+					{
+						Name:      "undo",
+						Usage:     "move the indices moved by ilm move back to their original step recorded in the journal",
+						ArgsUsage: "<journal>",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:    "dry-run",
+								Aliases: []string{"n"},
+								Usage:   "Dry run, don't actually execute move operation",
+							},
+							&cli.BoolFlag{
+								Name:    "force",
+								Aliases: []string{"f"},
+								Usage:   "Undo the move even if the journal has been written for a different deployment",
+							},
+						},
+						Action: ilmUndo,
+					},
+					{
+						Name:  "retention",
+						Usage: "show the space, which would be freed per tier by changing the min_age of the delete phase of ilm policies",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "delete-min-age",
+								Usage:    "Changed min_age of the delete phase, e.g. 30d, supported units: d, h, m, s",
+								Required: true,
+							},
+							&cli.BoolFlag{
+								Name:  "add-delete-phase",
+								Usage: "Include policies without delete phase as if a delete phase with the changed min_age would be added",
 							},
 							&cli.StringFlag{
 								Name:    "ilm-policy",
 								Aliases: []string{"i"},
-								Usage:   "Filter to only include indices with the given ILM policy attached",
+								Usage:   "Filter to only include the given ILM policy",
 							},
-							&cli.StringSliceFlag{
-								Name:    "sort",
-								Aliases: []string{"s"},
-								Usage:   "Sort indices by the given columns, allowed columns are: age, pri-size, total-size",
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"f"},
+								Usage:   "Format for the result: table, compact (default: table)",
+							},
+						},
+						Action: ilmRetention,
+					},
+					{
+						Name:  "browse",
+						Usage: "interactively browse ilm managed indices grouped by policy and phase and move, retry or remove the policy of single indices (prompt-based, entries are selected by entering their number)",
+						Flags: append(indexFilterFlags(),
+							&cli.Float64Flag{
+								Name:  "max-fill-pct",
+								Usage: "Maximum disk fill level of the target tier in percent after a move, the move is refused if exceeded (the low disk watermark applies, if lower)",
+								Value: 85.0,
+							},
+						),
+						Action: ilmBrowse,
+					},
+					{
+						Name:  "forecast",
+						Usage: "forecast the upcoming ilm phase transitions and the data volume entering and leaving each tier per day or week",
+						Flags: append(indexFilterFlags(),
+							&cli.IntFlag{
+								Name:  "days",
+								Usage: "Number of days to forecast",
+								Value: 14,
+							},
+							&cli.StringFlag{
+								Name:  "interval",
+								Usage: "Period to sum up the phase transitions: day, week",
+								Value: "day",
+							},
+							&cli.BoolFlag{
+								Name:  "with-indices",
+								Usage: "Show the individual phase transitions in addition to the sums per period and tier, only supported for the formats table, compact and markdown",
 							},
 							&cli.StringFlag{
-								Name:  "min-total-size",
-								Usage: "Minimum total size (primary shard + replicas) of index in order to be contained in the result, supported units: k, m, g, t, p",
+								Name:    "format",
+								Aliases: []string{"f"},
+								Usage:   "Format for the result: table, compact, markdown, json, ndjson, csv (default: table), sizes are in bytes for json, ndjson and csv",
 							},
+						),
+						Action: ilmForecast,
+					},
+					{
+						Name:  "stuck",
+						Usage: "find ilm managed indices, which are stuck in ilm, grouped by root cause",
+						Flags: []cli.Flag{
 							&cli.StringFlag{
-								Name:  "min-pri-size",
-								Usage: "Minimum primary shard size of index in order to be contained in the result, supported units: k, m, g, t, p",
+								Name:  "index-pattern",
+								Usage: "Pattern of the indices to check",
+								Value: "_all",
 							},
-							&cli.IntFlag{
-								Name:  "min-age-days",
-								Usage: "Minimum age of index in days in order to be contained in the result",
+							&cli.StringFlag{
+								Name:    "ilm-policy",
+								Aliases: []string{"i"},
+								Usage:   "Filter to only include indices with the given ILM policy attached",
+							},
+							&cli.StringFlag{
+								Name:  "threshold",
+								Usage: "Indices in the same step for longer than the threshold are considered stuck, supported units: d, h, m, s, 0 to disable",
+								Value: "1d",
 							},
 							&cli.StringFlag{
 								Name:    "format",
@@ -101,41 +284,182 @@ func run(ctx context.Context, args []string) error {
 								Usage:   "Format for the result: table, compact (default: table)",
 							},
 						},
-						Action: ilmList,
+						Action: ilmStuck,
 					},
 					{
-						Name:  "move",
-						Usage: "move ilm managed index to a different phase",
+						Name:  "retry",
+						Usage: "retry the failed step of ilm managed indices in the ERROR step",
 						Flags: []cli.Flag{
 							&cli.BoolFlag{
 								Name:    "dry-run",
 								Aliases: []string{"n"},
-								Usage:   "Dry run, don't actually execute move operation",
+								Usage:   "Dry run, don't actually execute retry operation",
 							},
-							&cli.BoolFlag{
-								Name:    "force",
+							&cli.StringFlag{
+								Name:     "index-pattern",
+								Usage:    "Pattern of the indices, which should be retried",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:    "ilm-policy",
+								Aliases: []string{"i"},
+								Usage:   "Filter to only retry indices with the given ILM policy attached",
+							},
+							&cli.StringFlag{
+								Name:    "phase",
+								Aliases: []string{"p"},
+								Usage:   "Filter to only retry indices in the given phase",
+							},
+							&cli.StringFlag{
+								Name:  "failed-step",
+								Usage: "Filter to only retry indices, which failed in the given step, e.g. check-rollover-ready",
+							},
+							&cli.DurationFlag{
+								Name:  "recheck-delay",
+								Usage: "Time to wait after the retry before checking, if the indices recovered",
+								Value: 10 * time.Second,
+							},
+							&cli.StringFlag{
+								Name:    "format",
 								Aliases: []string{"f"},
-								Usage:   "Try to move the index to the new phase even with pre condition checks failing",
+								Usage:   "Format for the result: table, compact (default: table)",
+							},
+						},
+						Action: ilmRetry,
+					},
+					{
+						Name:  "set-policy",
+						Usage: "change or remove the ilm policy of the matching ilm managed indices",
+						Flags: append(indexFilterFlags(),
+							&cli.BoolFlag{
+								Name:    "dry-run",
+								Aliases: []string{"n"},
+								Usage:   "Dry run, don't actually change the policy of the indices",
 							},
 							&cli.StringFlag{
 								Name:     "index-pattern",
-								Usage:    "Name of the index which should be moved to an other ILM tier",
+								Usage:    "Pattern of the indices, which policy should be changed",
 								Required: true,
 							},
 							&cli.StringFlag{
-								Name:     "target-phase",
-								Usage:    "Name of the phase the index should be moved to, valid values: hot, warm, cold, frozen, delete",
-								Required: true,
+								Name:  "policy",
+								Usage: "Name of the new ILM policy for the indices",
+							},
+							&cli.BoolFlag{
+								Name:  "remove",
+								Usage: "Remove the ILM policy from the indices instead of changing it",
+							},
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"f"},
+								Usage:   "Format for the list of matching indices: table, compact, markdown (default: table)",
+							},
+						),
+						Action: ilmSetPolicy,
+					},
+					{
+						Name:  "policies",
+						Usage: "list ilm policies with their phases and the indices using them",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "ilm-policy",
+								Aliases: []string{"i"},
+								Usage:   "Filter to only include the given ILM policy",
+							},
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"f"},
+								Usage:   "Format for the result: table, compact (default: table)",
+							},
+						},
+						Action: ilmPolicies,
+						Commands: []*cli.Command{
+							{
+								Name:      "diff",
+								Usage:     "show the differences between two ilm policies, optionally from two different deployments",
+								ArgsUsage: "<policy-a> [<policy-b>]",
+								Flags: []cli.Flag{
+									&cli.StringFlag{
+										Name:  "other-deployment",
+										Usage: "Name of the deployment in Elastic Cloud to read <policy-b> from, e.g. my-other-deployment (default: same deployment)",
+									},
+									&cli.StringFlag{
+										Name:  "other-region",
+										Usage: "Deployment region of the other deployment (default: same region)",
+									},
+									&cli.StringFlag{
+										Name:  "other-username",
+										Usage: "Username used to authenticate against the other deployment",
+									},
+									&cli.StringFlag{
+										Name:  "other-password",
+										Usage: "Password used to authenticate against the other deployment",
+									},
+									&cli.BoolFlag{
+										Name:    "exit-code",
+										Aliases: []string{"e"},
+										Usage:   "With this flag provided, the exit code will be set to none 0, if the policies differ",
+									},
+									&cli.StringFlag{
+										Name:    "format",
+										Aliases: []string{"f"},
+										Usage:   "Format for the result: table, compact (default: table)",
+									},
+								},
+								Action: ilmPoliciesDiff,
+							},
+							{
+								Name:  "export",
+								Usage: "export ilm policies as normalized files, one file per policy",
+								Flags: []cli.Flag{
+									&cli.StringFlag{
+										Name:     "dir",
+										Usage:    "Directory the policy files are written to",
+										Required: true,
+									},
+									&cli.StringFlag{
+										Name:    "format",
+										Aliases: []string{"f"},
+										Usage:   "Format of the policy files: json, yaml",
+										Value:   "json",
+									},
+									&cli.StringFlag{
+										Name:    "ilm-policy",
+										Aliases: []string{"i"},
+										Usage:   "Only export the given ILM policy",
+									},
+									&cli.BoolFlag{
+										Name:  "skip-managed",
+										Usage: "Skip policies managed by Elasticsearch (_meta.managed: true)",
+									},
+								},
+								Action: ilmPoliciesExport,
+							},
+							{
+								Name:  "apply",
+								Usage: "create or update ilm policies from the policy files (.json, .yaml, .yml) in a directory, policies not present as file are left untouched",
+								Flags: []cli.Flag{
+									&cli.StringFlag{
+										Name:     "dir",
+										Usage:    "Directory the policy files are read from, the file name without extension is used as policy name",
+										Required: true,
+									},
+									&cli.BoolFlag{
+										Name:    "dry-run",
+										Aliases: []string{"n"},
+										Usage:   "Dry run, only show the differences, don't actually create or update the policies",
+									},
+								},
+								Action: ilmPoliciesApply,
 							},
 						},
-						Action: ilmMove,
 					},
 				},
 			},
 			{
 				Name:  "profiles",
 				Usage: "return list of available profiles in a given region",
-				Flags: []cli.Flag{
+				Flags: append(templateSourceFlags(),
 					&cli.StringFlag{
 						Name:     "region",
 						Aliases:  []string{"r"},
@@ -143,14 +467,72 @@ func run(ctx context.Context, args []string) error {
 						Local:    true,
 						Required: true,
 					},
-				},
+					&cli.BoolFlag{
+						Name:  "details",
+						Usage: "Show the instance configurations per tier of each profile with instance type, storage and CPU multiplier and available sizes",
+						Local: true,
+					},
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Usage:   "Format for the result, requires --details: table, compact, markdown, json, ndjson, csv (default: table), sizes are in MiB for json, ndjson and csv",
+						Local:   true,
+					},
+				),
 				Action: listProfiles,
+				Commands: []*cli.Command{
+					{
+						Name:  "compare",
+						Usage: "compare the smallest configuration of each profile in the region, which keeps the required headroom for the current disk usage per tier of the deployment (costs are not shown, the Elastic Cloud API provides no prices)",
+						Flags: append(templateSourceFlags(),
+							&cli.Float64Flag{
+								Name:  "headroom-pct",
+								Usage: "Required available headroom in percent of the disk size of each tier",
+								Value: 25.0,
+							},
+							&cli.StringFlag{
+								Name:    "format",
+								Aliases: []string{"f"},
+								Usage:   "Format for the result: table, compact, markdown, json, ndjson, csv (default: table), sizes are in bytes for json, ndjson and csv",
+							},
+						),
+						Action: compareProfiles,
+					},
+				},
 			},
 			{
-				Name:   "regions",
-				Usage:  "return list of Elastic Cloud regions",
+				Name:  "regions",
+				Usage: "return list of Elastic Cloud regions with their availability, fetched from the Elastic Cloud API and cached for 24 hours",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "refresh",
+						Usage: "Fetch the regions from the Elastic Cloud API even if the cached regions are not yet expired",
+					},
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Usage:   "Format for the result: table, compact, markdown, json, ndjson, csv (default: table)",
+					},
+				},
 				Action: listRegions,
 			},
+			{
+				Name:  "templates",
+				Usage: "commands to manage the cached Elastic Cloud deployment templates",
+				Commands: []*cli.Command{
+					{
+						Name:  "sync",
+						Usage: "fetch the deployment templates of the region from the Elastic Cloud API into the cache, e.g. to use them with --offline",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "all-regions",
+								Usage: "Sync the deployment templates of all regions instead of only the given region",
+							},
+						},
+						Action: templatesSync,
+					},
+				},
+			},
 		},
 		Flags: []cli.Flag{
 			&cli.StringFlag{
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// outputFormats are the formats supported by renderColumns. The formats json,
+// ndjson and csv are meant to be processed by other tools and therefore
+// contain the raw values (e.g. sizes in bytes) instead of human readable
+// values.
+var outputFormats = []string{"table", "compact", "markdown", "json", "ndjson", "csv"}
+
+// validateOutputFormat returns an error, if format is not one of
+// outputFormats. The empty format is the same as table.
+func validateOutputFormat(format string) error {
+	if format != "" && !slices.Contains(outputFormats, format) {
+		return fmt.Errorf("format %q is not supported, use one of %v", format, outputFormats)
+	}
+
+	return nil
+}
+
+// tableFormats are the human readable formats of outputFormats. Commands,
+// which mix tables with progress or status messages, only support these.
+var tableFormats = []string{"table", "compact", "markdown"}
+
+// validateTableFormat returns an error, if format is not one of tableFormats.
+// The empty format is the same as table.
+func validateTableFormat(format string) error {
+	if format != "" && !slices.Contains(tableFormats, format) {
+		return fmt.Errorf("format %q is not supported, use one of %v", format, tableFormats)
+	}
+
+	return nil
+}
+
+// column describes a column of the result rows of type T.
+type column[T any] struct {
+	// header is the column header in the table formats.
+	header string
+	// key is the column name in the machine readable formats.
+	key string
+	// value returns the human readable value of the column.
+	value func(T) string
+	// raw returns the raw value of the column for the machine readable
+	// formats.
+	raw func(T) any
+}
+
+// renderColumns writes the rows with the given columns in the given format to
+// w. See outputFormats for the supported formats.
+func renderColumns[T any](w io.Writer, format string, columns []column[T], rows []T) error {
+	switch format {
+	case "json":
+		records := make([]json.RawMessage, 0, len(rows))
+		for _, row := range rows {
+			record, err := columnRecord(columns, row)
+			if err != nil {
+				return err
+			}
+
+			records = append(records, record)
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+
+	case "ndjson":
+		encoder := json.NewEncoder(w)
+		for _, row := range rows {
+			record, err := columnRecord(columns, row)
+			if err != nil {
+				return err
+			}
+
+			err = encoder.Encode(record)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case "csv":
+		writer := csv.NewWriter(w)
+
+		header := make([]string, 0, len(columns))
+		for _, col := range columns {
+			header = append(header, col.key)
+		}
+
+		err := writer.Write(header)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			record := make([]string, 0, len(columns))
+			for _, col := range columns {
+				record = append(record, csvValue(col.raw(row)))
+			}
+
+			err = writer.Write(record)
+			if err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	}
+
+	header := make([]string, 0, len(columns))
+	for _, col := range columns {
+		header = append(header, col.header)
+	}
+
+	data := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		values := make([]string, 0, len(columns))
+		for _, col := range columns {
+			values = append(values, col.value(row))
+		}
+
+		data = append(data, values)
+	}
+
+	table := newTable(w, format)
+	table.Header(header)
+	err := table.Bulk(data)
+	if err != nil {
+		return err
+	}
+
+	return table.Render()
+}
+
+// csvValue formats a raw value for csv. nil is an empty value and the
+// elements of lists are separated by comma.
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []int:
+		elements := make([]string, 0, len(v))
+		for _, element := range v {
+			elements = append(elements, strconv.Itoa(element))
+		}
+
+		return strings.Join(elements, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// columnRecord returns the raw values of the columns of row as JSON object.
+// The keys are kept in the order of the columns.
+func columnRecord[T any](columns []column[T], row T) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, col := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(col.key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(col.raw(row))
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_renderIndexDetails(t *testing.T) {
+	indices := []indexDetails{
+		{name: "logs-1", dataStream: "logs", writeIndex: true, phase: "hot", action: "complete", step: "complete", policy: "logs", age: 2 * day, priSize: 1024, totalSize: 2048},
+		{name: "logs,2", phase: "warm", action: "forcemerge", step: "segment-count", policy: "logs", age: time.Hour, priSize: 10, totalSize: 20},
+	}
+
+	tests := []struct {
+		format string
+
+		want string
+	}{
+		{
+			format: "json",
+
+			want: `[
+  {
+    "index": "logs-1",
+    "data_stream": "logs",
+    "write_index": true,
+    "phase": "hot",
+    "action": "complete",
+    "step": "complete",
+    "policy": "logs",
+    "age_seconds": 172800,
+    "pri_size_bytes": 1024,
+    "total_size_bytes": 2048
+  },
+  {
+    "index": "logs,2",
+    "data_stream": "",
+    "write_index": false,
+    "phase": "warm",
+    "action": "forcemerge",
+    "step": "segment-count",
+    "policy": "logs",
+    "age_seconds": 3600,
+    "pri_size_bytes": 10,
+    "total_size_bytes": 20
+  }
+]
+`,
+		},
+		{
+			format: "ndjson",
+
+			want: `{"index":"logs-1","data_stream":"logs","write_index":true,"phase":"hot","action":"complete","step":"complete","policy":"logs","age_seconds":172800,"pri_size_bytes":1024,"total_size_bytes":2048}
+{"index":"logs,2","data_stream":"","write_index":false,"phase":"warm","action":"forcemerge","step":"segment-count","policy":"logs","age_seconds":3600,"pri_size_bytes":10,"total_size_bytes":20}
+`,
+		},
+		{
+			format: "csv",
+
+			want: `index,data_stream,write_index,phase,action,step,policy,age_seconds,pri_size_bytes,total_size_bytes
+logs-1,logs,true,hot,complete,complete,logs,172800,1024,2048
+"logs,2",,false,warm,forcemerge,segment-count,logs,3600,10,20
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+
+			err := renderIndexDetails(buf, tc.format, indices)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.want, buf.String())
+		})
+	}
+}
+
+func Test_renderIndexDetails_markdown(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	err := renderIndexDetails(buf, "markdown", []indexDetails{{name: "logs-1", dataStream: "logs", writeIndex: true, phase: "hot", priSize: 1024}})
+	require.NoError(t, err)
+
+	require.Contains(t, buf.String(), "| logs-1 ")
+	require.Contains(t, buf.String(), "1KiB")
+}
+
+func Test_validateOutputFormat(t *testing.T) {
+	require.NoError(t, validateOutputFormat(""))
+	require.NoError(t, validateOutputFormat("ndjson"))
+	require.Error(t, validateOutputFormat("xml"))
+}
+
+func Test_validateTableFormat(t *testing.T) {
+	require.NoError(t, validateTableFormat(""))
+	require.NoError(t, validateTableFormat("markdown"))
+	require.Error(t, validateTableFormat("json"))
+}